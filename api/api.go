@@ -0,0 +1,233 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/catalinfl/nuisance/control"
+)
+
+const (
+	TickEvent    = "tick"
+	ModeEvent    = "mode"
+	WarningEvent = "warning"
+)
+
+type Event struct {
+	Type   string         `json:"type"`
+	Status control.Status `json:"status"`
+}
+
+// Server is the optional localhost REST + Server-Sent Events API used by
+// dashboards and status bar widgets.
+type Server struct {
+	ctrl  control.Controller
+	token string
+	// the one browser origin allowed to call the API, if any
+	origin string
+	srv    *http.Server
+
+	mu   sync.Mutex
+	subs map[chan Event]struct{}
+}
+
+// New returns a server for ctrl. origin is the browser origin, such as
+// http://localhost:8080, allowed to call the API from a page; empty
+// allows none.
+func New(addr, token, origin string, ctrl control.Controller) *Server {
+	s := &Server{
+		ctrl:   ctrl,
+		token:  token,
+		origin: origin,
+		subs:   make(map[chan Event]struct{}),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", s.handleStatus)
+	mux.HandleFunc("GET /events", s.handleEvents)
+	for _, cmd := range []string{"start", "pause", "resume", "reset"} {
+		mux.HandleFunc("POST /"+cmd, s.handleCommand(cmd))
+	}
+
+	s.srv = &http.Server{
+		Addr:              addr,
+		Handler:           s.auth(mux),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	return s
+}
+
+// Start listens on the configured address, which must be a loopback
+// address, and serves in the background.
+func (s *Server) Start() error {
+	host, _, err := net.SplitHostPort(s.srv.Addr)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return fmt.Errorf("api address %s is not a loopback address", s.srv.Addr)
+	}
+	if s.token == "" {
+		return errors.New("api token is empty")
+	}
+
+	ln, err := net.Listen("tcp", s.srv.Addr)
+	if err != nil {
+		return err
+	}
+	go s.srv.Serve(ln)
+	return nil
+}
+
+func (s *Server) Close() error {
+	s.mu.Lock()
+	for ch := range s.subs {
+		close(ch)
+		delete(s.subs, ch)
+	}
+	s.mu.Unlock()
+	return s.srv.Close()
+}
+
+// Publish sends an event to every connected SSE client. Slow clients
+// miss events rather than block the timer.
+func (s *Server) Publish(ev Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch := range s.subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+func (s *Server) auth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// only the configured dashboard origin may script the API
+		w.Header().Set("Vary", "Origin")
+		if s.origin != "" && r.Header.Get("Origin") == s.origin {
+			w.Header().Set("Access-Control-Allow-Origin", s.origin)
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST")
+		}
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		// EventSource cannot set headers, so /events alone takes ?token=;
+		// anywhere else a query token would only end up in logs
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" && r.URL.Path == "/events" {
+			token = r.URL.Query().Get("token")
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.ctrl.Status())
+}
+
+func (s *Server) handleCommand(cmd string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status, err := control.Run(s.ctrl, cmd)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, status)
+	}
+}
+
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	ch := make(chan Event, 16)
+	s.mu.Lock()
+	s.subs[ch] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		if _, ok := s.subs[ch]; ok {
+			delete(s.subs, ch)
+			close(ch)
+		}
+		s.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	// start every stream with the current state
+	writeEvent(w, Event{Type: ModeEvent, Status: s.ctrl.Status()})
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(30 * time.Second)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case ev, ok := <-ch:
+			if !ok {
+				return
+			}
+			writeEvent(w, ev)
+			flusher.Flush()
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, ev Event) {
+	data, _ := json.Marshal(ev.Status)
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Type, data)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// LoadToken reads the API token from path, creating a random one the
+// first time.
+func LoadToken(path string) (string, error) {
+	input, err := os.ReadFile(path)
+	if err == nil && len(strings.TrimSpace(string(input))) > 0 {
+		return strings.TrimSpace(string(input)), nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(buf)
+	return token, os.WriteFile(path, []byte(token+"\n"), 0600)
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/catalinfl/nuisance/control"
+)
+
+type fakeController struct {
+	mu     sync.Mutex
+	starts int
+}
+
+func (c *fakeController) Start() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.starts++
+	return nil
+}
+
+func (c *fakeController) startCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.starts
+}
+
+func (c *fakeController) Pause() error  { return nil }
+func (c *fakeController) Resume() error { return nil }
+func (c *fakeController) Reset() error  { return nil }
+func (c *fakeController) Show() error   { return nil }
+
+func (c *fakeController) Status() control.Status {
+	return control.Status{Mode: "paused", Label: "Paused"}
+}
+
+const testToken = "secret"
+
+func serve(t *testing.T, origin string) (*httptest.Server, *fakeController) {
+	t.Helper()
+	ctrl := &fakeController{}
+	ts := httptest.NewServer(New("127.0.0.1:0", testToken, origin, ctrl).srv.Handler)
+	t.Cleanup(ts.Close)
+	return ts, ctrl
+}
+
+func do(t *testing.T, method, url string, header map[string]string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp
+}
+
+func TestHeaderToken(t *testing.T) {
+	ts, ctrl := serve(t, "")
+	auth := map[string]string{"Authorization": "Bearer " + testToken}
+
+	if resp := do(t, "GET", ts.URL+"/status", auth); resp.StatusCode != http.StatusOK {
+		t.Fatalf("status with header: %d", resp.StatusCode)
+	}
+	if resp := do(t, "POST", ts.URL+"/start", auth); resp.StatusCode != http.StatusOK {
+		t.Fatalf("start with header: %d", resp.StatusCode)
+	}
+	bad := map[string]string{"Authorization": "Bearer wrong"}
+	if resp := do(t, "GET", ts.URL+"/status", bad); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("status with wrong token: %d", resp.StatusCode)
+	}
+	if n := ctrl.startCount(); n != 1 {
+		t.Fatalf("starts = %d, want 1", n)
+	}
+}
+
+func TestQueryTokenOnlyOnEvents(t *testing.T) {
+	ts, ctrl := serve(t, "")
+
+	if resp := do(t, "GET", ts.URL+"/status?token="+testToken, nil); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("status with query token: %d", resp.StatusCode)
+	}
+	if resp := do(t, "POST", ts.URL+"/start?token="+testToken, nil); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("start with query token: %d", resp.StatusCode)
+	}
+	if ctrl.startCount() != 0 {
+		t.Fatalf("start ran without a header token")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", ts.URL+"/events?token="+testToken, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("events with query token: %d", resp.StatusCode)
+	}
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line != "event: "+ModeEvent+"\n" {
+		t.Fatalf("first line = %q", line)
+	}
+}
+
+func TestCORS(t *testing.T) {
+	const dashboard = "http://localhost:8080"
+	auth := map[string]string{"Authorization": "Bearer " + testToken, "Origin": dashboard}
+
+	ts, _ := serve(t, "")
+	if got := do(t, "GET", ts.URL+"/status", auth).Header.Get("Access-Control-Allow-Origin"); got != "" {
+		t.Fatalf("no origin configured, got Allow-Origin %q", got)
+	}
+
+	ts, _ = serve(t, dashboard)
+	if got := do(t, "GET", ts.URL+"/status", auth).Header.Get("Access-Control-Allow-Origin"); got != dashboard {
+		t.Fatalf("configured origin, got Allow-Origin %q", got)
+	}
+	preflight := do(t, "OPTIONS", ts.URL+"/start", map[string]string{"Origin": dashboard})
+	if preflight.StatusCode != http.StatusNoContent || !strings.Contains(preflight.Header.Get("Access-Control-Allow-Headers"), "Authorization") {
+		t.Fatalf("preflight: %d %v", preflight.StatusCode, preflight.Header)
+	}
+
+	other := map[string]string{"Authorization": "Bearer " + testToken, "Origin": "http://evil.example"}
+	if got := do(t, "GET", ts.URL+"/status", other).Header.Get("Access-Control-Allow-Origin"); got != "" {
+		t.Fatalf("other origin, got Allow-Origin %q", got)
+	}
+}
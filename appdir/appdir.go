@@ -0,0 +1,39 @@
+package appdir
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Dir returns the directory used for nuisance's own data files,
+// creating it if needed. It falls back to the executable's directory
+// when no per-user config directory is available.
+func Dir() string {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		exePath, err := os.Executable()
+		if err != nil {
+			return "."
+		}
+		return filepath.Dir(exePath)
+	}
+	dir := filepath.Join(base, "nuisance")
+	_ = os.MkdirAll(dir, 0755)
+	return dir
+}
+
+func File(name string) string {
+	return filepath.Join(Dir(), name)
+}
+
+// CacheDir returns a per-user directory for files nuisance can recreate
+// at any time, such as the synthesized sounds. Unlike a shared temp
+// directory, only the user can write to it.
+func CacheDir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(base, "nuisance")
+	return dir, os.MkdirAll(dir, 0700)
+}
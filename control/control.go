@@ -0,0 +1,50 @@
+package control
+
+import "fmt"
+
+// Status is the timer state reported to other processes.
+type Status struct {
+	Mode         string   `json:"mode"`
+	Label        string   `json:"label"`
+	Remaining    int      `json:"remaining"`
+	Clock        string   `json:"clock"`
+	Cycle        int      `json:"cycle"`
+	Task         string   `json:"task,omitempty"`
+	Blocking     bool     `json:"blocking"`
+	BlockedSites []string `json:"blocked_sites"`
+}
+
+// Controller drives the running timer on behalf of IPC and API clients.
+type Controller interface {
+	Start() error
+	Pause() error
+	Resume() error
+	Reset() error
+	Show() error
+	Status() Status
+}
+
+// Commands lists the names accepted by Run.
+var Commands = []string{"start", "pause", "resume", "reset", "show", "status"}
+
+// Run dispatches a command name to the controller and returns the
+// resulting status.
+func Run(c Controller, cmd string) (Status, error) {
+	var err error
+	switch cmd {
+	case "start":
+		err = c.Start()
+	case "pause":
+		err = c.Pause()
+	case "resume":
+		err = c.Resume()
+	case "reset":
+		err = c.Reset()
+	case "show":
+		err = c.Show()
+	case "status":
+	default:
+		return Status{}, fmt.Errorf("unknown command %q", cmd)
+	}
+	return c.Status(), err
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+
+	"github.com/catalinfl/nuisance/control"
+	"github.com/catalinfl/nuisance/ipc"
+	"github.com/catalinfl/nuisance/notify"
+	"github.com/catalinfl/nuisance/pomodoro"
+	"github.com/catalinfl/nuisance/tray"
+	"github.com/catalinfl/nuisance/ui"
+)
+
+// appController runs the same actions as the Start/Pause/Reset buttons
+// for other processes, under the lock held by the frame loop.
+type appController struct {
+	mu         *sync.Mutex
+	start      func()
+	pause      func()
+	resume     func()
+	reset      func()
+	show       func()
+	status     func() control.Status
+	invalidate func()
+}
+
+func (c *appController) do(fn func()) error {
+	c.mu.Lock()
+	fn()
+	c.mu.Unlock()
+	c.invalidate()
+	return nil
+}
+
+func (c *appController) Start() error  { return c.do(c.start) }
+func (c *appController) Pause() error  { return c.do(c.pause) }
+func (c *appController) Resume() error { return c.do(c.resume) }
+func (c *appController) Reset() error  { return c.do(c.reset) }
+func (c *appController) Show() error   { return c.do(c.show) }
+
+func (c *appController) Status() control.Status {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.status()
+}
+
+func timerStatus(pomoTimer *pomodoro.PomodoroTimer, blocking bool, sites []string, task string) control.Status {
+	ts := pomoTimer.State()
+	mode, remaining := ts.Mode, ts.Remaining
+	if pomoTimer.Flowtime && (mode == pomodoro.WorkMode || (mode == pomodoro.PauseMode && ts.PreviousMode == pomodoro.WorkMode)) {
+		remaining = ts.Elapsed
+	}
+
+	st := control.Status{
+		Mode:         mode.String(),
+		Label:        modeLabel(mode, pomoTimer.Flowtime),
+		Remaining:    int(remaining.Seconds()),
+		Clock:        formatClock(remaining),
+		Cycle:        ts.Cycle,
+		Task:         task,
+		Blocking:     blocking,
+		BlockedSites: []string{},
+	}
+	if blocking {
+		st.BlockedSites = append(st.BlockedSites, sites...)
+	}
+	return st
+}
+
+// trayStatus puts the minutes left on the tray icon, colored by mode.
+func trayStatus(st control.Status) tray.Status {
+	ts := tray.Status{
+		Minutes: (st.Remaining + 59) / 60,
+		Tooltip: "Nuisance: " + st.Label + " " + st.Clock,
+	}
+	switch st.Mode {
+	case pomodoro.WorkMode.String():
+		ts.Color, ts.Running = ui.ModeColor(pomodoro.WorkMode), true
+	case pomodoro.BreakMode.String():
+		ts.Color, ts.Running = ui.ModeColor(pomodoro.BreakMode), true
+	case pomodoro.WorkAlarmMode.String(), pomodoro.BreakAlarmMode.String():
+		ts.Color, ts.Minutes = ui.ModeColor(pomodoro.WorkAlarmMode), 0
+	case pomodoro.PauseMode.String():
+		ts.Color = ui.ModeColor(pomodoro.PauseMode)
+	default:
+		ts.Color, ts.Minutes = ui.ModeColor(pomodoro.IdleMode), -1
+		ts.Tooltip = "Nuisance: " + st.Label
+	}
+	return ts
+}
+
+func isCommand(name string) bool {
+	return slices.Contains(control.Commands, name)
+}
+
+// runCommand sends a command to the running app and prints its status.
+func runCommand(cmd string) int {
+	resp, err := ipc.Send(ipc.SocketPath(), cmd)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nuisance: %v\n", err)
+		return 1
+	}
+	output, _ := json.MarshalIndent(resp.Status, "", "  ")
+	fmt.Println(string(output))
+	return 0
+}
+
+// handOff passes a second launch on to the instance that is already
+// running: bring its window up and optionally start the timer.
+func handOff(start bool) int {
+	if _, err := ipc.Send(ipc.SocketPath(), "show"); err != nil {
+		fmt.Fprintf(os.Stderr, "nuisance: already running, but it did not answer: %v\n", err)
+		return 1
+	}
+	if start {
+		if _, err := ipc.Send(ipc.SocketPath(), "start"); err != nil {
+			fmt.Fprintf(os.Stderr, "nuisance: %v\n", err)
+			return 1
+		}
+	}
+	return 0
+}
+
+// notification buttons
+const (
+	actionStartBreak = "start-break"
+	actionStartWork  = "start-work"
+)
+
+// phaseNotification is the notification for the end of a work session
+// or of a break, with a button to start the next phase.
+func phaseNotification(mode pomodoro.Mode, breakMinutes int, onAction func(id string)) notify.Notification {
+	if mode == pomodoro.BreakAlarmMode {
+		return notify.Notification{
+			Title:    "Break is over",
+			Body:     "Ready for the next session?",
+			Actions:  []notify.Action{{ID: actionStartWork, Label: "Start work"}},
+			OnAction: onAction,
+		}
+	}
+	return notify.Notification{
+		Title:    "Work session complete",
+		Body:     fmt.Sprintf("Time for a %d minute break.", breakMinutes),
+		Actions:  []notify.Action{{ID: actionStartBreak, Label: "Start break"}},
+		OnAction: onAction,
+	}
+}
+
+// startPhase carries out a notification button through send, which runs
+// a control command and returns the status after it. "Start work" after
+// a break goes through Ready to a new session. A button from a stale
+// notification does nothing.
+func startPhase(action string, send func(cmd string) (control.Status, error)) error {
+	st, err := send("status")
+	if err != nil {
+		return err
+	}
+	switch {
+	case action == actionStartBreak && st.Mode == pomodoro.WorkAlarmMode.String():
+		_, err = send("start")
+	case action == actionStartWork && st.Mode == pomodoro.BreakAlarmMode.String():
+		if st, err = send("start"); err == nil && st.Mode == pomodoro.IdleMode.String() {
+			_, err = send("start")
+		}
+	}
+	return err
+}
+
+// runURL handles a nuisance: link opened by a Windows notification
+// button by passing it to the running instance.
+func runURL(uri string) int {
+	action, ok := notify.ParseURI(uri)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "nuisance: not a nuisance link: %s\n", uri)
+		return 1
+	}
+	err := startPhase(action, func(cmd string) (control.Status, error) {
+		resp, err := ipc.Send(ipc.SocketPath(), cmd)
+		return resp.Status, err
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nuisance: %v\n", err)
+		return 1
+	}
+	return 0
+}
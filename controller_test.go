@@ -0,0 +1,130 @@
+package main
+
+import (
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/catalinfl/nuisance/control"
+	"github.com/catalinfl/nuisance/notify"
+	"github.com/catalinfl/nuisance/pomodoro"
+)
+
+// fakeTimer answers control commands the way the timer moves through
+// its modes on "start".
+type fakeTimer struct {
+	mode pomodoro.Mode
+	sent []string
+}
+
+func (f *fakeTimer) send(cmd string) (control.Status, error) {
+	f.sent = append(f.sent, cmd)
+	if cmd == "start" {
+		switch f.mode {
+		case pomodoro.WorkAlarmMode:
+			f.mode = pomodoro.BreakMode
+		case pomodoro.BreakAlarmMode:
+			f.mode = pomodoro.IdleMode
+		case pomodoro.IdleMode:
+			f.mode = pomodoro.WorkMode
+		}
+	}
+	return control.Status{Mode: f.mode.String()}, nil
+}
+
+// recordingNotifier is a fake notify.Notifier that keeps every
+// notification. Press stands in for the user clicking a button.
+type recordingNotifier struct {
+	mu   sync.Mutex
+	sent []notify.Notification
+}
+
+func (r *recordingNotifier) Notify(n notify.Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent = append(r.sent, n)
+	return nil
+}
+
+// Press invokes the action with the given ID on the latest notification
+// that offers it, as if its button had been clicked. It reports whether
+// there was one.
+func (r *recordingNotifier) Press(id string) bool {
+	r.mu.Lock()
+	var onAction func(string)
+	for i := len(r.sent) - 1; i >= 0 && onAction == nil; i-- {
+		for _, a := range r.sent[i].Actions {
+			if a.ID == id && r.sent[i].OnAction != nil {
+				onAction = r.sent[i].OnAction
+				break
+			}
+		}
+	}
+	r.mu.Unlock()
+	if onAction == nil {
+		return false
+	}
+	onAction(id)
+	return true
+}
+
+// pressOn shows the notification for the timer's mode, presses button
+// id on it and runs what comes back through startPhase.
+func pressOn(t *testing.T, timer *fakeTimer, id string) bool {
+	t.Helper()
+	actions := make(chan string, 1)
+	n := &recordingNotifier{}
+	_ = n.Notify(phaseNotification(timer.mode, 5, func(id string) { actions <- id }))
+	if !n.Press(id) {
+		return false
+	}
+	if err := startPhase(<-actions, timer.send); err != nil {
+		t.Fatal(err)
+	}
+	return true
+}
+
+func TestStartBreakButton(t *testing.T) {
+	timer := &fakeTimer{mode: pomodoro.WorkAlarmMode}
+	if !pressOn(t, timer, actionStartBreak) {
+		t.Fatal("work alarm notification has no Start break button")
+	}
+	if timer.mode != pomodoro.BreakMode {
+		t.Fatalf("mode = %v, want break", timer.mode)
+	}
+	if want := []string{"status", "start"}; !slices.Equal(timer.sent, want) {
+		t.Fatalf("sent %q, want %q", timer.sent, want)
+	}
+}
+
+func TestStartWorkButton(t *testing.T) {
+	timer := &fakeTimer{mode: pomodoro.BreakAlarmMode}
+	if !pressOn(t, timer, actionStartWork) {
+		t.Fatal("break alarm notification has no Start work button")
+	}
+	if timer.mode != pomodoro.WorkMode {
+		t.Fatalf("mode = %v, want work", timer.mode)
+	}
+}
+
+func TestPhaseNotificationButtons(t *testing.T) {
+	timer := &fakeTimer{mode: pomodoro.WorkAlarmMode}
+	if pressOn(t, timer, actionStartWork) {
+		t.Fatal("work alarm notification offers Start work")
+	}
+	timer.mode = pomodoro.BreakAlarmMode
+	if pressOn(t, timer, actionStartBreak) {
+		t.Fatal("break alarm notification offers Start break")
+	}
+}
+
+func TestStalePhaseButton(t *testing.T) {
+	// the alarm was answered in the window before the button was pressed
+	timer := &fakeTimer{mode: pomodoro.BreakMode}
+	if err := startPhase(actionStartBreak, timer.send); err != nil {
+		t.Fatal(err)
+	}
+	if timer.mode != pomodoro.BreakMode || !slices.Equal(timer.sent, []string{"status"}) {
+		t.Fatalf("stale button sent %q", timer.sent)
+	}
+}
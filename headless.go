@@ -0,0 +1,111 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/catalinfl/nuisance/appdir"
+	"github.com/catalinfl/nuisance/history"
+	"github.com/catalinfl/nuisance/httpblock"
+	"github.com/catalinfl/nuisance/instance"
+	"github.com/catalinfl/nuisance/pomodoro"
+)
+
+// runHeadless drives the timer and the blocker from a terminal, for
+// machines reached over SSH or tmux.
+func runHeadless(args []string) int {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	work := fs.Int("work", 25, "work minutes")
+	brk := fs.Int("break", 5, "break minutes")
+	cycles := fs.Int("cycles", 0, "stop after this many pomodoros (0 runs until Ctrl-C)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *work <= 0 || *brk <= 0 {
+		fmt.Fprintln(os.Stderr, "nuisance: --work and --break must be positive")
+		return 2
+	}
+
+	lock, err := instance.Acquire(appdir.File("nuisance.lock"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nuisance: %v\n", err)
+		return 1
+	}
+	defer lock.Release()
+
+	b := httpblock.Blocker{
+		Token: "nuisance",
+		Sites: defaultSites,
+	}
+	attachHelper(&b)
+	pomoTimer := pomodoro.NewPomodoroTimer(*work, *brk)
+	recorder := history.NewRecorder(openHistory())
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+
+	// remove any existing blocks on startup
+	_ = b.RemoveBlockEntries()
+
+	startWork := func() {
+		if err := b.AddBlockEntries(); err != nil {
+			fmt.Fprintf(os.Stderr, "\nnuisance: blocking failed: %v\n", err)
+		}
+		recorder.Begin(history.PomodoroMode, pomoTimer.WorkDuration, "", 0, b.Sites)
+		pomoTimer.Start()
+	}
+	startWork()
+
+	// the loop only ends here; cleanup shuts the timer down after it.
+	// The timer's run goroutine changes the mode, so it is only read
+	// through State.
+	completed := 0
+	lastMode := pomoTimer.Mode()
+loop:
+	for {
+		var remaining time.Duration
+		select {
+		case <-sigCh:
+			break loop
+		case r, ok := <-pomoTimer.Updates:
+			if !ok {
+				break loop
+			}
+			remaining = r
+		}
+
+		// one snapshot per update, so the mode and cycle printed belong
+		// together
+		st := pomoTimer.State()
+		mode := st.Mode
+		if mode != lastMode {
+			lastMode = mode
+			switch mode {
+			case pomodoro.WorkAlarmMode:
+				_, _ = recorder.Finish(true)
+				_ = b.RemoveBlockEntries()
+				completed++
+				fmt.Printf("\rPomodoro %d complete%20s\n", completed, "")
+				if *cycles > 0 && completed >= *cycles {
+					break loop
+				}
+				pomoTimer.StartBreak()
+			case pomodoro.BreakAlarmMode:
+				fmt.Printf("\rBreak over%30s\n", "")
+				pomoTimer.Stop()
+				startWork()
+			}
+			continue
+		}
+
+		fmt.Printf("\r%-10s %s  pomodoro %d  ", modeLabel(mode, false), formatClock(remaining), st.Cycle)
+	}
+
+	cleanupSession(&b, pomoTimer, recorder)
+	fmt.Println()
+	return 0
+}
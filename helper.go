@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/catalinfl/nuisance/httpblock"
+)
+
+const heartbeatInterval = 10 * time.Second
+
+// runHelper is `nuisance helper`, the privileged service that edits the
+// hosts file on behalf of an unprivileged GUI.
+func runHelper(args []string) int {
+	fs := flag.NewFlagSet("helper", flag.ContinueOnError)
+	addr := fs.String("addr", httpblock.DefaultHelperAddr, "localhost address to listen on")
+	tokenFile := fs.String("token-file", httpblock.DefaultHelperTokenPath(), "shared secret, created if missing")
+	timeout := fs.Duration("timeout", 3*heartbeatInterval, "revert blocks after this long without a heartbeat")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	token, err := httpblock.LoadHelperToken(*tokenFile, true)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nuisance helper: %v\n", err)
+		return 1
+	}
+
+	h := httpblock.NewHelperServer(*addr, token, *timeout)
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		_ = h.Shutdown()
+		os.Exit(0)
+	}()
+
+	if err := h.ListenAndServe(); err != nil {
+		_ = h.Shutdown()
+		fmt.Fprintf(os.Stderr, "nuisance helper: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// attachHelper routes blocking through the helper when one is running
+// and its token is readable; otherwise b keeps editing the hosts file
+// itself, which needs admin rights.
+func attachHelper(b *httpblock.Blocker) {
+	token, err := httpblock.LoadHelperToken(httpblock.DefaultHelperTokenPath(), false)
+	if err != nil || token == "" {
+		return
+	}
+	client := httpblock.NewHelperClient(httpblock.DefaultHelperAddr, token)
+	if err := client.Heartbeat(); err != nil {
+		return
+	}
+	b.Backend = client
+	go client.KeepAlive(heartbeatInterval, nil)
+}
@@ -0,0 +1,45 @@
+package history
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func WriteJSON(w io.Writer, sessions []Session) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sessions)
+}
+
+func WriteCSV(w io.Writer, sessions []Session) error {
+	cw := csv.NewWriter(w)
+	header := []string{"start", "end", "mode", "completed", "planned_min", "actual_min", "pauses", "interruptions", "task", "blocked_sites", "unacknowledged"}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, s := range sessions {
+		record := []string{
+			s.Start.Format(time.RFC3339),
+			s.End.Format(time.RFC3339),
+			s.Mode,
+			strconv.FormatBool(s.Completed),
+			strconv.FormatFloat(s.Planned.Minutes(), 'f', 1, 64),
+			strconv.FormatFloat(s.Actual.Minutes(), 'f', 1, 64),
+			strconv.Itoa(s.Pauses),
+			strconv.Itoa(len(s.Interruptions)),
+			s.Task,
+			strings.Join(s.BlockedSites, " "),
+			strconv.FormatBool(s.Unacknowledged),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,71 @@
+package history
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func exportStore(t *testing.T) (*Store, time.Time) {
+	t.Helper()
+	// a Wednesday
+	now := time.Date(2026, 10, 14, 15, 0, 0, 0, time.UTC)
+	s := &Store{}
+	for _, start := range []time.Time{
+		now.Add(-2 * time.Hour),              // today
+		now.AddDate(0, 0, -2),                // Monday, same week
+		now.AddDate(0, 0, -3),                // Sunday, last week
+		now.AddDate(0, 0, 1).Add(-time.Hour), // tomorrow
+	} {
+		sess := Session{Start: start, End: start.Add(25 * time.Minute), Mode: PomodoroMode, Completed: true, Task: "write"}
+		if err := s.Append(sess); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return s, now
+}
+
+func TestDayAndWeek(t *testing.T) {
+	s, now := exportStore(t)
+	if got := len(s.Day(now)); got != 1 {
+		t.Fatalf("Day: %d sessions, want 1", got)
+	}
+	if got := len(s.Week(now)); got != 3 {
+		t.Fatalf("Week: %d sessions, want 3", got)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	s, now := exportStore(t)
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, s.Week(now)); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 4 {
+		t.Fatalf("%d records, want a header and 3 sessions", len(records))
+	}
+	if records[0][0] != "start" || records[1][8] != "write" {
+		t.Fatalf("unexpected records %q", records[:2])
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	s, _ := exportStore(t)
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, s.Sessions()); err != nil {
+		t.Fatal(err)
+	}
+	var back []Session
+	if err := json.Unmarshal(buf.Bytes(), &back); err != nil {
+		t.Fatal(err)
+	}
+	if len(back) != 4 || !back[0].Start.Equal(s.Sessions()[0].Start) {
+		t.Fatalf("round trip gave %d sessions", len(back))
+	}
+}
@@ -0,0 +1,190 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	PomodoroMode = "pomodoro"
+	FlowtimeMode = "flowtime"
+)
+
+const (
+	InternalInterruption = "internal"
+	ExternalInterruption = "external"
+)
+
+type Interruption struct {
+	Time time.Time `json:"time"`
+	Kind string    `json:"kind"`
+	Note string    `json:"note,omitempty"`
+}
+
+type Session struct {
+	Start         time.Time      `json:"start"`
+	End           time.Time      `json:"end"`
+	Planned       time.Duration  `json:"planned"`
+	Actual        time.Duration  `json:"actual"`
+	Mode          string         `json:"mode"`
+	Completed     bool           `json:"completed"`
+	Pauses        int            `json:"pauses"`
+	Paused        time.Duration  `json:"paused"`
+	Interruptions []Interruption `json:"interruptions,omitempty"`
+	Task          string         `json:"task,omitempty"`
+	TaskID        int            `json:"task_id,omitempty"`
+	BlockedSites  []string       `json:"blocked_sites,omitempty"`
+	// the end alarm went unanswered until it silenced itself
+	Unacknowledged bool `json:"unacknowledged,omitempty"`
+}
+
+// Store is an append-only JSONL log of sessions. A session is keyed by
+// its start time; when a later line repeats one, the later line wins.
+// The zero value keeps sessions in memory only.
+type Store struct {
+	mu       sync.Mutex
+	path     string
+	sessions []Session
+	// the file ends in a line torn by a crash, so the next write has to
+	// start on a new line
+	torn bool
+}
+
+func Open(path string) (*Store, error) {
+	s := &Store{path: path}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	seen := make(map[int64]int)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		s.torn = false
+		var sess Session
+		// skip lines torn by a crash mid-write
+		if err := json.Unmarshal(scanner.Bytes(), &sess); err != nil {
+			s.torn = true
+			continue
+		}
+		if i, ok := seen[sess.Start.UnixNano()]; ok {
+			s.sessions[i] = sess
+			continue
+		}
+		seen[sess.Start.UnixNano()] = len(s.sessions)
+		s.sessions = append(s.sessions, sess)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(s.sessions, func(i, j int) bool {
+		return s.sessions[i].Start.Before(s.sessions[j].Start)
+	})
+	return s, nil
+}
+
+func (s *Store) Append(sess Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sessions = append(s.sessions, sess)
+	return s.write(sess)
+}
+
+// Update replaces the stored session with the same start time by
+// appending the new version.
+func (s *Store) Update(sess Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	found := false
+	for i := range s.sessions {
+		if s.sessions[i].Start.Equal(sess.Start) {
+			s.sessions[i] = sess
+			found = true
+		}
+	}
+	if !found {
+		s.sessions = append(s.sessions, sess)
+	}
+	return s.write(sess)
+}
+
+func (s *Store) write(sess Session) error {
+	if s.path == "" {
+		return nil
+	}
+
+	line, err := json.Marshal(sess)
+	if err != nil {
+		return err
+	}
+	if s.torn {
+		line = append([]byte("\n"), line...)
+	}
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	s.torn = false
+	return nil
+}
+
+func (s *Store) Sessions() []Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Session(nil), s.sessions...)
+}
+
+// Range returns the sessions that started in [from, to).
+func (s *Store) Range(from, to time.Time) []Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []Session
+	for _, sess := range s.sessions {
+		if !sess.Start.Before(from) && sess.Start.Before(to) {
+			out = append(out, sess)
+		}
+	}
+	return out
+}
+
+func (s *Store) Day(t time.Time) []Session {
+	start := StartOfDay(t)
+	return s.Range(start, start.AddDate(0, 0, 1))
+}
+
+// Week returns the sessions of the Monday-based week containing t.
+func (s *Store) Week(t time.Time) []Session {
+	start := StartOfWeek(t)
+	return s.Range(start, start.AddDate(0, 0, 7))
+}
+
+func StartOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+func StartOfWeek(t time.Time) time.Time {
+	day := StartOfDay(t)
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
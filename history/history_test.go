@@ -0,0 +1,78 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestOpenReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	start := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := Session{Start: start, End: start.Add(25 * time.Minute), Mode: PomodoroMode, Completed: true}
+	second := Session{Start: start.Add(time.Hour), End: start.Add(time.Hour + 25*time.Minute), Mode: PomodoroMode}
+	_ = s.Append(second)
+	_ = s.Append(first)
+	// the alarm went unanswered: a later line for the same session
+	first.Unacknowledged = true
+	_ = s.Update(first)
+
+	// a crash tore the last write
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"start":"2026-10-14T12:00:00Z","mo`)
+	_ = f.Close()
+
+	s, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := s.Sessions()
+	if len(got) != 2 {
+		t.Fatalf("%d sessions, want 2", len(got))
+	}
+	if !got[0].Start.Equal(first.Start) || !got[1].Start.Equal(second.Start) {
+		t.Fatalf("sessions not sorted by start: %v, %v", got[0].Start, got[1].Start)
+	}
+	if !got[0].Unacknowledged {
+		t.Fatal("the later line for a session did not win")
+	}
+
+	// appending after a torn line still gives a readable file
+	third := Session{Start: start.Add(2 * time.Hour), Mode: PomodoroMode}
+	if err := s.Append(third); err != nil {
+		t.Fatal(err)
+	}
+	s, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Sessions(); len(got) != 3 || !got[2].Start.Equal(third.Start) {
+		t.Fatalf("session appended after the torn line was lost: %d sessions", len(got))
+	}
+}
+
+func TestOpenMissing(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "history.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Sessions()) != 0 {
+		t.Fatal("new store is not empty")
+	}
+}
+
+func TestOpenUnreadable(t *testing.T) {
+	// a directory where the file should be
+	if _, err := Open(t.TempDir()); err == nil {
+		t.Fatal("Open accepted a directory")
+	}
+}
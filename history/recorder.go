@@ -0,0 +1,136 @@
+package history
+
+import (
+	"sync"
+	"time"
+)
+
+// Recorder tracks the session in progress and appends it to a Store
+// when it finishes.
+type Recorder struct {
+	mu       sync.Mutex
+	store    *Store
+	current  *Session
+	pausedAt time.Time
+}
+
+func NewRecorder(store *Store) *Recorder {
+	return &Recorder{store: store}
+}
+
+func (r *Recorder) Begin(mode string, planned time.Duration, task string, taskID int, sites []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.current = &Session{
+		Start:        time.Now(),
+		Planned:      planned,
+		Mode:         mode,
+		Task:         task,
+		TaskID:       taskID,
+		BlockedSites: append([]string(nil), sites...),
+	}
+	r.pausedAt = time.Time{}
+}
+
+// Current returns a copy of the session in progress.
+func (r *Recorder) Current() (Session, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current == nil {
+		return Session{}, false
+	}
+	sess := *r.current
+	return sess, true
+}
+
+// Restore continues a session saved before a restart. A non-zero
+// pausedAt is when the session was paused; otherwise it was running and
+// downSince is when it was last saved. Either way the time the app was
+// down counts as paused, not as focus time.
+func (r *Recorder) Restore(sess Session, pausedAt, downSince time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.current = &sess
+	r.pausedAt = pausedAt
+	if pausedAt.IsZero() && !downSince.IsZero() {
+		r.current.Paused += max(time.Since(downSince), 0)
+	}
+}
+
+// PausedAt returns when the current session was paused, or the zero
+// time if it is running.
+func (r *Recorder) PausedAt() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.pausedAt
+}
+
+func (r *Recorder) Active() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current != nil
+}
+
+func (r *Recorder) Pause() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.current == nil || !r.pausedAt.IsZero() {
+		return
+	}
+	r.current.Pauses++
+	r.pausedAt = time.Now()
+}
+
+func (r *Recorder) Resume() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.current == nil || r.pausedAt.IsZero() {
+		return
+	}
+	r.current.Paused += time.Since(r.pausedAt)
+	r.pausedAt = time.Time{}
+}
+
+func (r *Recorder) Interrupt(kind, note string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.current == nil {
+		return
+	}
+	r.current.Interruptions = append(r.current.Interruptions, Interruption{
+		Time: time.Now(),
+		Kind: kind,
+		Note: note,
+	})
+}
+
+// Finish closes the current session and stores it. It does nothing if
+// no session is in progress.
+func (r *Recorder) Finish(completed bool) (Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.current == nil {
+		return Session{}, nil
+	}
+	sess := *r.current
+	r.current = nil
+
+	sess.End = time.Now()
+	if !r.pausedAt.IsZero() {
+		sess.Paused += sess.End.Sub(r.pausedAt)
+		r.pausedAt = time.Time{}
+	}
+	sess.Actual = (sess.End.Sub(sess.Start) - sess.Paused).Round(time.Second)
+	sess.Completed = completed
+
+	if r.store == nil {
+		return sess, nil
+	}
+	return sess, r.store.Append(sess)
+}
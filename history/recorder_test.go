@@ -0,0 +1,45 @@
+package history
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRestorePausedKeepsPauseStart(t *testing.T) {
+	now := time.Now()
+	sess := Session{
+		Start:   now.Add(-time.Hour),
+		Planned: 25 * time.Minute,
+		Mode:    PomodoroMode,
+		Pauses:  1,
+	}
+	// ran for ten minutes, then paused and the app was down since
+	r := NewRecorder(nil)
+	r.Restore(sess, now.Add(-50*time.Minute), now.Add(-45*time.Minute))
+
+	got, err := r.Finish(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Actual != 10*time.Minute {
+		t.Fatalf("actual = %v, want 10m", got.Actual)
+	}
+}
+
+func TestRestoreRunning(t *testing.T) {
+	now := time.Now()
+	sess := Session{Start: now.Add(-30 * time.Minute), Mode: PomodoroMode}
+	// last saved after five minutes of work; the app was down since
+	r := NewRecorder(nil)
+	r.Restore(sess, time.Time{}, now.Add(-25*time.Minute))
+	if !r.PausedAt().IsZero() {
+		t.Fatal("running session restored as paused")
+	}
+	got, _ := r.Finish(true)
+	if got.Actual != 5*time.Minute {
+		t.Fatalf("actual = %v, want 5m without the downtime", got.Actual)
+	}
+	if got.Pauses != 0 {
+		t.Fatalf("pauses = %d, downtime is not a pause the user took", got.Pauses)
+	}
+}
@@ -0,0 +1,111 @@
+package history
+
+import (
+	"sort"
+	"time"
+)
+
+type DayStat struct {
+	Day       time.Time
+	Minutes   float64
+	Pomodoros int
+}
+
+type HourStat struct {
+	Hour  int
+	Count int
+}
+
+type Stats struct {
+	// Days runs from the Monday `weeks` weeks back up to today, oldest first.
+	Days              []DayStat
+	TodayMinutes      float64
+	TodayPomodoros    int
+	WeekMinutes       float64
+	TotalPomodoros    int
+	Streak            int
+	WeekInternal      int
+	WeekExternal      int
+	InterruptionHours [24]int
+}
+
+func Compute(sessions []Session, now time.Time, weeks int) Stats {
+	var st Stats
+
+	today := StartOfDay(now)
+	first := StartOfWeek(now).AddDate(0, 0, -7*(weeks-1))
+	for d := first; !d.After(today); d = d.AddDate(0, 0, 1) {
+		st.Days = append(st.Days, DayStat{Day: d})
+	}
+
+	perDay := make(map[int64]*DayStat)
+	for i := range st.Days {
+		perDay[st.Days[i].Day.Unix()] = &st.Days[i]
+	}
+
+	completedDays := make(map[int64]bool)
+	weekStart := StartOfWeek(now)
+	for _, s := range sessions {
+		day := StartOfDay(s.Start.In(now.Location()))
+		if s.Completed {
+			st.TotalPomodoros++
+			completedDays[day.Unix()] = true
+		}
+		if ds, ok := perDay[day.Unix()]; ok {
+			ds.Minutes += s.Actual.Minutes()
+			if s.Completed {
+				ds.Pomodoros++
+			}
+		}
+		thisWeek := !day.Before(weekStart)
+		if thisWeek {
+			st.WeekMinutes += s.Actual.Minutes()
+		}
+		for _, in := range s.Interruptions {
+			st.InterruptionHours[in.Time.In(now.Location()).Hour()]++
+			if !thisWeek {
+				continue
+			}
+			if in.Kind == InternalInterruption {
+				st.WeekInternal++
+			} else {
+				st.WeekExternal++
+			}
+		}
+	}
+
+	if ds, ok := perDay[today.Unix()]; ok {
+		st.TodayMinutes = ds.Minutes
+		st.TodayPomodoros = ds.Pomodoros
+	}
+
+	// a streak is still alive if today has nothing yet
+	day := today
+	if !completedDays[day.Unix()] {
+		day = day.AddDate(0, 0, -1)
+	}
+	for completedDays[day.Unix()] {
+		st.Streak++
+		day = day.AddDate(0, 0, -1)
+	}
+
+	return st
+}
+
+// TopInterruptionHours returns up to n hours with the most
+// interruptions, busiest first.
+func (st Stats) TopInterruptionHours(n int) []HourStat {
+	var hours []HourStat
+	for h, c := range st.InterruptionHours {
+		if c > 0 {
+			hours = append(hours, HourStat{Hour: h, Count: c})
+		}
+	}
+	sort.SliceStable(hours, func(i, j int) bool {
+		return hours[i].Count > hours[j].Count
+	})
+	if len(hours) > n {
+		hours = hours[:n]
+	}
+	return hours
+}
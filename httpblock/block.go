@@ -6,14 +6,24 @@ import (
 	"strings"
 )
 
-const hostsPath = `C:\Windows\System32\drivers\etc\hosts`
+// Backend applies blocks somewhere other than the local hosts file,
+// such as the privileged helper.
+type Backend interface {
+	Apply(sites []string) error
+	Revert() error
+}
 
 type Blocker struct {
-	Token string
-	Sites []string
+	Token   string
+	Sites   []string
+	Backend Backend
 }
 
 func (b *Blocker) AddBlockEntries() error {
+	if b.Backend != nil {
+		return b.Backend.Apply(b.Sites)
+	}
+
 	input, err := os.ReadFile(hostsPath)
 	if err != nil && !os.IsNotExist(err) {
 		return err
@@ -67,6 +77,10 @@ func (b *Blocker) AddBlockEntries() error {
 }
 
 func (b *Blocker) RemoveBlockEntries() error {
+	if b.Backend != nil {
+		return b.Backend.Revert()
+	}
+
 	input, err := os.ReadFile(hostsPath)
 	if err != nil {
 		return err
@@ -84,9 +98,15 @@ func (b *Blocker) RemoveBlockEntries() error {
 		out = append(out, l)
 	}
 
-	output := strings.Join(out, "\r\n")
-	if !strings.HasSuffix(output, "\r\n") {
-		output += "\r\n"
+	// keep the file's own line endings; /etc/hosts must not gain CRLFs
+	newline := "\n"
+	if strings.Contains(string(input), "\r\n") {
+		newline = "\r\n"
+	}
+
+	output := strings.Join(out, newline)
+	if !strings.HasSuffix(output, newline) {
+		output += newline
 	}
 	return os.WriteFile(hostsPath, []byte(output), 0644)
 }
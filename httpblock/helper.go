@@ -0,0 +1,275 @@
+package httpblock
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const DefaultHelperAddr = "127.0.0.1:7421"
+
+// HelperServer is the privileged side of blocking: a small local service
+// that owns the hosts file so the GUI can run without admin rights. If
+// the GUI stops sending heartbeats while sites are blocked, the helper
+// reverts the blocks on its own.
+type HelperServer struct {
+	Addr    string
+	Token   string
+	Timeout time.Duration
+
+	mu        sync.Mutex
+	blocker   Blocker
+	applied   bool
+	lastHeard time.Time
+}
+
+func NewHelperServer(addr, token string, timeout time.Duration) *HelperServer {
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	return &HelperServer{
+		Addr:    addr,
+		Token:   token,
+		Timeout: timeout,
+		blocker: Blocker{Token: "nuisance"},
+	}
+}
+
+// ListenAndServe reverts leftover blocks, then serves until the
+// listener fails.
+func (h *HelperServer) ListenAndServe() error {
+	if h.Token == "" {
+		return errors.New("helper token is empty")
+	}
+	_ = h.blocker.RemoveBlockEntries()
+
+	ln, err := net.Listen("tcp", h.Addr)
+	if err != nil {
+		return err
+	}
+	go h.watchdog(nil)
+
+	srv := &http.Server{Handler: h.handler(), ReadHeaderTimeout: 5 * time.Second}
+	return srv.Serve(ln)
+}
+
+func (h *HelperServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /apply", h.handleApply)
+	mux.HandleFunc("POST /revert", h.handleRevert)
+	mux.HandleFunc("POST /heartbeat", h.handleHeartbeat)
+	return h.auth(mux)
+}
+
+// Shutdown reverts any blocks still applied.
+func (h *HelperServer) Shutdown() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.applied = false
+	return h.blocker.RemoveBlockEntries()
+}
+
+// watchdog reverts the blocks once heartbeats stop, until stop is
+// closed.
+func (h *HelperServer) watchdog(stop <-chan struct{}) {
+	ticker := time.NewTicker(h.Timeout / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+		h.mu.Lock()
+		if h.applied && time.Since(h.lastHeard) > h.Timeout {
+			h.applied = false
+			_ = h.blocker.RemoveBlockEntries()
+		}
+		h.mu.Unlock()
+	}
+}
+
+func (h *HelperServer) auth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(h.Token)) != 1 {
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (h *HelperServer) handleApply(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Sites []string `json:"sites"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for _, site := range req.Sites {
+		if !validHost(site) {
+			http.Error(w, fmt.Sprintf("invalid host %q", site), http.StatusBadRequest)
+			return
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastHeard = time.Now()
+	h.blocker.Sites = req.Sites
+	if err := h.blocker.AddBlockEntries(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.applied = true
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *HelperServer) handleRevert(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastHeard = time.Now()
+	h.applied = false
+	if err := h.blocker.RemoveBlockEntries(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *HelperServer) handleHeartbeat(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	h.lastHeard = time.Now()
+	h.mu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// validHost keeps anything that could break out of a hosts line out of
+// the file the helper writes as root. Only DNS names are accepted; an IP
+// address in the name column would redirect that address instead.
+func validHost(site string) bool {
+	if site == "" || len(site) > 253 || net.ParseIP(site) != nil {
+		return false
+	}
+	for _, label := range strings.Split(site, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, c := range label {
+			ok := c == '-' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+			if !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// HelperClient is the GUI side of the helper. It implements Backend.
+type HelperClient struct {
+	Addr   string
+	Token  string
+	client http.Client
+}
+
+func NewHelperClient(addr, token string) *HelperClient {
+	return &HelperClient{
+		Addr:   addr,
+		Token:  token,
+		client: http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (c *HelperClient) post(path string, body any) error {
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(http.MethodPost, "http://"+c.Addr+path, &buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("helper: %s %s", path, resp.Status)
+	}
+	return nil
+}
+
+func (c *HelperClient) Apply(sites []string) error {
+	return c.post("/apply", map[string][]string{"sites": sites})
+}
+
+func (c *HelperClient) Revert() error {
+	return c.post("/revert", nil)
+}
+
+func (c *HelperClient) Heartbeat() error {
+	return c.post("/heartbeat", nil)
+}
+
+// KeepAlive sends heartbeats until stop is closed.
+func (c *HelperClient) KeepAlive(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			_ = c.Heartbeat()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// DefaultHelperTokenPath is where the helper keeps its shared secret.
+func DefaultHelperTokenPath() string {
+	if dir := os.Getenv("ProgramData"); dir != "" {
+		return filepath.Join(dir, "nuisance", "helper.token")
+	}
+	return "/etc/nuisance/helper.token"
+}
+
+// LoadHelperToken reads the helper token, creating it when create is set.
+func LoadHelperToken(path string, create bool) (string, error) {
+	input, err := os.ReadFile(path)
+	if err == nil {
+		return strings.TrimSpace(string(input)), nil
+	}
+	if !create || !os.IsNotExist(err) {
+		return "", err
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(buf)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	// group-readable so the unprivileged GUI user can be let in
+	return token, os.WriteFile(path, []byte(token+"\n"), 0640)
+}
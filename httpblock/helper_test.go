@@ -0,0 +1,164 @@
+package httpblock
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scratchHosts points hostsPath at a temporary copy for the test.
+func scratchHosts(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "hosts")
+	if err := os.WriteFile(path, []byte("127.0.0.1\tlocalhost\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := hostsPath
+	hostsPath = path
+	t.Cleanup(func() { hostsPath = old })
+	return path
+}
+
+func blocked(t *testing.T, path, site string) bool {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Contains(string(data), "\t"+site+"\t")
+}
+
+func TestValidHost(t *testing.T) {
+	long := strings.Repeat("a", 64)
+	tests := []struct {
+		host string
+		ok   bool
+	}{
+		{"example.com", true},
+		{"www.web.whatsapp.com", true},
+		{"xn--bcher-kva.example", true},
+		{"localhost", true},
+		{strings.Repeat("a", 63) + ".com", true},
+		{"", false},
+		{"example.com\n127.0.0.1 bank.com", false},
+		{"example.com\r", false},
+		{"exa mple.com", false},
+		{"example.com\t#", false},
+		{"example.com#comment", false},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"2001:db8::1", false},
+		{long + ".com", false},
+		{strings.Repeat("a.", 127) + "com", false},
+		{"example..com", false},
+		{".example.com", false},
+		{"-example.com", false},
+		{"example-.com", false},
+		{"exämple.com", false},
+	}
+	for _, tt := range tests {
+		if got := validHost(tt.host); got != tt.ok {
+			t.Errorf("validHost(%q) = %v, want %v", tt.host, got, tt.ok)
+		}
+	}
+}
+
+func post(t *testing.T, url, token, body string) int {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestHelperAuth(t *testing.T) {
+	hosts := scratchHosts(t)
+	h := NewHelperServer("", "secret", time.Minute)
+	srv := httptest.NewServer(h.handler())
+	defer srv.Close()
+
+	body := `{"sites":["example.com"]}`
+	for _, token := range []string{"", "wrong", "secre", "secret2"} {
+		if code := post(t, srv.URL+"/apply", token, body); code != http.StatusUnauthorized {
+			t.Errorf("token %q: status %d, want 401", token, code)
+		}
+	}
+	if blocked(t, hosts, "example.com") {
+		t.Fatal("unauthorized apply changed the hosts file")
+	}
+
+	if code := post(t, srv.URL+"/apply", "secret", body); code != http.StatusNoContent {
+		t.Fatalf("status %d, want 204", code)
+	}
+	if !blocked(t, hosts, "example.com") {
+		t.Fatal("apply did not block example.com")
+	}
+	if code := post(t, srv.URL+"/revert", "wrong", ""); code != http.StatusUnauthorized {
+		t.Errorf("revert with a wrong token: status %d, want 401", code)
+	}
+	if !blocked(t, hosts, "example.com") {
+		t.Fatal("unauthorized revert changed the hosts file")
+	}
+}
+
+func TestHelperRejectsBadHost(t *testing.T) {
+	hosts := scratchHosts(t)
+	h := NewHelperServer("", "secret", time.Minute)
+	srv := httptest.NewServer(h.handler())
+	defer srv.Close()
+
+	body := `{"sites":["example.com","evil.com\n0.0.0.0 bank.com"]}`
+	if code := post(t, srv.URL+"/apply", "secret", body); code != http.StatusBadRequest {
+		t.Fatalf("status %d, want 400", code)
+	}
+	if blocked(t, hosts, "example.com") {
+		t.Fatal("a request with a bad host was partly applied")
+	}
+}
+
+func TestHelperRevertsWithoutHeartbeat(t *testing.T) {
+	hosts := scratchHosts(t)
+	h := NewHelperServer("", "secret", 150*time.Millisecond)
+	srv := httptest.NewServer(h.handler())
+	defer srv.Close()
+	stop := make(chan struct{})
+	defer close(stop)
+	go h.watchdog(stop)
+
+	if code := post(t, srv.URL+"/apply", "secret", `{"sites":["example.com"]}`); code != http.StatusNoContent {
+		t.Fatalf("status %d, want 204", code)
+	}
+
+	// heartbeats keep the blocks in place past the timeout
+	for range 6 {
+		time.Sleep(50 * time.Millisecond)
+		post(t, srv.URL+"/heartbeat", "secret", "")
+	}
+	if !blocked(t, hosts, "example.com") {
+		t.Fatal("blocks reverted while heartbeats arrived")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for blocked(t, hosts, "example.com") {
+		if time.Now().After(deadline) {
+			t.Fatal("blocks not reverted after heartbeats stopped")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if data, _ := os.ReadFile(hosts); !strings.Contains(string(data), "localhost") {
+		t.Fatal("revert removed the file's own entries")
+	}
+}
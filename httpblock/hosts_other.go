@@ -0,0 +1,6 @@
+//go:build !windows
+
+package httpblock
+
+// a variable so tests can point it at a scratch file
+var hostsPath = "/etc/hosts"
@@ -0,0 +1,4 @@
+package httpblock
+
+// a variable so tests can point it at a scratch file
+var hostsPath = `C:\Windows\System32\drivers\etc\hosts`
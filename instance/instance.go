@@ -0,0 +1,30 @@
+package instance
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrLocked means another nuisance process holds the lock.
+var ErrLocked = errors.New("nuisance is already running")
+
+// Lock guards against two processes editing the hosts file with the
+// same token. The OS drops it when the process exits, even on a crash.
+type Lock struct {
+	f *os.File
+}
+
+func Acquire(path string) (*Lock, error) {
+	f, err := lockFile(path)
+	if err != nil {
+		return nil, err
+	}
+	_ = f.Truncate(0)
+	_, _ = fmt.Fprintf(f, "%d\n", os.Getpid())
+	return &Lock{f: f}, nil
+}
+
+func (l *Lock) Release() error {
+	return l.f.Close()
+}
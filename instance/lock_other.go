@@ -0,0 +1,11 @@
+//go:build !unix && !windows
+
+package instance
+
+import "os"
+
+// no file locking here: Acquire always succeeds, so a second instance
+// is not detected on these platforms
+func lockFile(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+}
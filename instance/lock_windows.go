@@ -0,0 +1,33 @@
+//go:build windows
+
+package instance
+
+import (
+	"os"
+	"syscall"
+)
+
+const errSharingViolation syscall.Errno = 32
+
+// lockFile opens path without sharing, so a second process fails to
+// open it until the first one exits.
+func lockFile(path string) (*os.File, error) {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, err
+	}
+	h, err := syscall.CreateFile(name,
+		syscall.GENERIC_READ|syscall.GENERIC_WRITE,
+		0,
+		nil,
+		syscall.OPEN_ALWAYS,
+		syscall.FILE_ATTRIBUTE_NORMAL,
+		0)
+	if err != nil {
+		if err == errSharingViolation {
+			return nil, ErrLocked
+		}
+		return nil, err
+	}
+	return os.NewFile(uintptr(h), path), nil
+}
@@ -0,0 +1,111 @@
+package ipc
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/catalinfl/nuisance/appdir"
+	"github.com/catalinfl/nuisance/control"
+)
+
+// ErrNotRunning is returned by Send when no instance is listening.
+var ErrNotRunning = errors.New("nuisance is not running")
+
+type Response struct {
+	OK     bool           `json:"ok"`
+	Error  string         `json:"error,omitempty"`
+	Status control.Status `json:"status"`
+}
+
+// SocketPath is the Unix socket the running app listens on. Windows 10
+// and later support AF_UNIX sockets too, so the same path scheme works
+// on both.
+func SocketPath() string {
+	return appdir.File("nuisance.sock")
+}
+
+type Server struct {
+	ln   net.Listener
+	path string
+	ctrl control.Controller
+}
+
+func Listen(path string, ctrl control.Controller) (*Server, error) {
+	// a socket file left behind by a crash blocks Listen
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		return nil, errors.New("another instance is already listening on " + path)
+	}
+	_ = os.Remove(path)
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{ln: ln, path: path, ctrl: ctrl}
+	go s.serve()
+	return s, nil
+}
+
+func (s *Server) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil && line == "" {
+		return
+	}
+
+	var resp Response
+	status, err := control.Run(s.ctrl, strings.TrimSpace(line))
+	resp.Status = status
+	resp.OK = err == nil
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	_ = json.NewEncoder(conn).Encode(resp)
+}
+
+func (s *Server) Close() error {
+	err := s.ln.Close()
+	_ = os.Remove(s.path)
+	return err
+}
+
+// Send runs a command in the instance listening on path.
+func Send(path, cmd string) (Response, error) {
+	var resp Response
+
+	conn, err := net.DialTimeout("unix", path, 2*time.Second)
+	if err != nil {
+		return resp, ErrNotRunning
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	if _, err := conn.Write([]byte(cmd + "\n")); err != nil {
+		return resp, err
+	}
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return resp, err
+	}
+	if !resp.OK {
+		return resp, errors.New(resp.Error)
+	}
+	return resp, nil
+}
@@ -1,10 +1,13 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"image/color"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -12,20 +15,80 @@ import (
 	"time"
 
 	"gioui.org/app"
+	"gioui.org/io/key"
 	"gioui.org/io/system"
 	"gioui.org/op"
 	"gioui.org/unit"
+	"gioui.org/widget"
 	"gioui.org/widget/material"
+	"github.com/catalinfl/nuisance/api"
+	"github.com/catalinfl/nuisance/appdir"
+	"github.com/catalinfl/nuisance/control"
+	"github.com/catalinfl/nuisance/history"
 	"github.com/catalinfl/nuisance/httpblock"
+	"github.com/catalinfl/nuisance/instance"
+	"github.com/catalinfl/nuisance/ipc"
+	"github.com/catalinfl/nuisance/notify"
 	"github.com/catalinfl/nuisance/pomodoro"
+	"github.com/catalinfl/nuisance/recovery"
 	"github.com/catalinfl/nuisance/sound"
+	"github.com/catalinfl/nuisance/speech"
+	"github.com/catalinfl/nuisance/tasks"
+	"github.com/catalinfl/nuisance/tray"
 	"github.com/catalinfl/nuisance/ui"
 	"github.com/catalinfl/nuisance/window"
 )
 
+// sites to block
+// how often the crash snapshot is refreshed while the timer runs
+const snapshotEvery = 15 * time.Second
+
+var defaultSites = []string{
+	"www.facebook.com", "facebook.com",
+	"www.youtube.com", "youtube.com", "m.youtube.com",
+	"www.twitter.com", "twitter.com", "x.com", "www.x.com",
+	"www.reddit.com", "reddit.com",
+	"www.instagram.com", "instagram.com",
+	"www.tiktok.com", "tiktok.com",
+	"www.web.whatsapp.com", "web.whatsapp.com",
+}
+
+type options struct {
+	apiAddr   string
+	apiToken  string
+	apiOrigin string
+	start     bool
+}
+
 func main() {
-	var handler window.WindowHandler = window.WindowHandler{}
-	go runApp(&handler)
+	if len(os.Args) > 1 && os.Args[1] == "run" {
+		os.Exit(runHeadless(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "helper" {
+		os.Exit(runHelper(os.Args[2:]))
+	}
+	if len(os.Args) > 2 && os.Args[1] == "open-url" {
+		os.Exit(runURL(os.Args[2]))
+	}
+	if len(os.Args) > 1 && isCommand(os.Args[1]) {
+		os.Exit(runCommand(os.Args[1]))
+	}
+
+	var opts options
+	flag.StringVar(&opts.apiAddr, "api", "", "serve the HTTP API on this localhost address, e.g. 127.0.0.1:7420")
+	flag.StringVar(&opts.apiToken, "api-token", "", "token for the HTTP API (default: generated and saved to api.token)")
+	flag.StringVar(&opts.apiOrigin, "api-origin", "", "browser origin allowed to call the HTTP API, e.g. http://localhost:8080")
+	flag.BoolVar(&opts.start, "start", false, "start a work session right away")
+	flag.Parse()
+
+	// a second launch hands over to the running instance and exits
+	lock, err := instance.Acquire(appdir.File("nuisance.lock"))
+	if errors.Is(err, instance.ErrLocked) {
+		os.Exit(handOff(opts.start))
+	}
+
+	var handler window.WindowController = &window.WindowHandler{}
+	go runApp(handler, opts, lock)
 	app.Main()
 }
 
@@ -61,38 +124,144 @@ func updateBlocker(b *httpblock.Blocker, state *ui.AppState) {
 	b.Sites = sitesToBlock
 }
 
-func runApp(winHandler *window.WindowHandler) {
+func formatClock(d time.Duration) string {
+	minutes := int(d.Minutes())
+	seconds := int(d.Seconds()) % 60
+	return fmt.Sprintf("%02d:%02d", minutes, seconds)
+}
+
+func modeLabel(mode pomodoro.Mode, flowtime bool) string {
+	switch mode {
+	case pomodoro.WorkMode:
+		if flowtime {
+			return "Flow Time"
+		}
+		return "Work Time"
+	case pomodoro.BreakMode:
+		return "Break Time"
+	case pomodoro.PauseMode:
+		return "Paused"
+	case pomodoro.WorkAlarmMode:
+		return "Work Complete - Press Start for Break"
+	case pomodoro.BreakAlarmMode:
+		return "Break Complete - Press Start for Work"
+	}
+	return "Ready"
+}
+
+func openHistory() *history.Store {
+	store, err := history.Open(appdir.File("history.jsonl"))
+	if err != nil {
+		// an in-memory store leaves the unreadable file as it is
+		fmt.Fprintf(os.Stderr, "nuisance: history.jsonl: %v; sessions will not be saved\n", err)
+		return &history.Store{}
+	}
+	return store
+}
+
+// exportSessions writes the sessions in scope to the exports folder and
+// returns the line shown under the export buttons.
+func exportSessions(store *history.Store, scope int, format string) string {
+	now := time.Now()
+	sessions, name := store.Sessions(), "all"
+	switch scope {
+	case ui.ExportToday:
+		sessions, name = store.Day(now), now.Format("2006-01-02")
+	case ui.ExportWeek:
+		sessions, name = store.Week(now), "week-"+history.StartOfWeek(now).Format("2006-01-02")
+	}
+
+	write := history.WriteCSV
+	if format == "json" {
+		write = history.WriteJSON
+	}
+	dir := appdir.File("exports")
+	path := filepath.Join(dir, "nuisance-"+name+"."+format)
+	err := os.MkdirAll(dir, 0755)
+	if err == nil {
+		var f *os.File
+		if f, err = os.Create(path); err == nil {
+			err = write(f, sessions)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+		}
+	}
+	if err != nil {
+		return "Export failed: " + err.Error()
+	}
+	return fmt.Sprintf("Saved %d sessions to %s", len(sessions), path)
+}
+
+// cleanupSession is the shutdown path shared by the GUI and headless
+// modes: close the session, drop the crash snapshot and unblock sites.
+func cleanupSession(b *httpblock.Blocker, pomoTimer *pomodoro.PomodoroTimer, recorder *history.Recorder) {
+	_, _ = recorder.Finish(false)
+	_ = recovery.Clear(appdir.File("session.json"))
+	_ = b.RemoveBlockEntries()
+	pomoTimer.Shutdown()
+}
+
+func idleTime(state *ui.AppState) string {
+	if state.Flowtime {
+		return "00:00"
+	}
+	return fmt.Sprintf("%02d:00", state.WorkMinutes)
+}
+
+func runApp(winHandler window.WindowController, opts options, lock *instance.Lock) {
+	if lock != nil {
+		defer lock.Release()
+	}
+
 	w := new(app.Window)
 	w.Option(
 		app.Size(unit.Dp(360), unit.Dp(320)),
 		app.Title("Nuisance"),
 	)
 
-	// sites to block
-	defaultSites := []string{
-		"www.facebook.com", "facebook.com",
-		"www.youtube.com", "youtube.com", "m.youtube.com",
-		"www.twitter.com", "twitter.com", "x.com", "www.x.com",
-		"www.reddit.com", "reddit.com",
-		"www.instagram.com", "instagram.com",
-		"www.tiktok.com", "tiktok.com",
-		"www.web.whatsapp.com", "web.whatsapp.com",
-	}
-
 	b := httpblock.Blocker{
 		Token: "nuisance",
 		Sites: defaultSites,
 	}
+	attachHelper(&b)
 
 	var hwnd atomic.Uintptr
+	// the mini timer window, while it is open
+	var miniWin atomic.Pointer[app.Window]
+	invalidateMini := func() {
+		if mw := miniWin.Load(); mw != nil {
+			mw.Invalidate()
+		}
+	}
 
 	// init here
 	pomoTimer := pomodoro.NewPomodoroTimer(25, 5)
 
+	store := openHistory()
+	recorder := history.NewRecorder(store)
+	taskList, err := tasks.Load(appdir.File("tasks.json"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nuisance: tasks.json: %v; task changes will not be saved\n", err)
+	}
+	snapshotPath := appdir.File("session.json")
+
+	beginSession := func() {
+		mode, planned := history.PomodoroMode, pomoTimer.WorkDuration
+		if pomoTimer.Flowtime {
+			mode, planned = history.FlowtimeMode, 0
+		}
+		task, taskID := "", 0
+		if t, ok := taskList.Current(); ok {
+			task, taskID = t.Title, t.ID
+		}
+		recorder.Begin(mode, planned, task, taskID, b.Sites)
+	}
+
 	var cleanupOnce sync.Once
 	cleanup := func() {
-		_ = b.RemoveBlockEntries()
-		pomoTimer.Shutdown()
+		tray.Stop()
+		cleanupSession(&b, pomoTimer, recorder)
 	}
 
 	sigCh := make(chan os.Signal, 1)
@@ -124,6 +293,7 @@ func runApp(winHandler *window.WindowHandler) {
 	th.Palette.ContrastBg = color.NRGBA{R: 160, G: 32, B: 240, A: 255}
 	btns := ui.NewButtons()
 	settingsBtns := ui.NewSettingsButtons()
+	taskBtns := ui.NewTaskButtons()
 	state := &ui.AppState{
 		CurrentTab:  0,
 		AlwaysOnTop: true,
@@ -140,27 +310,234 @@ func runApp(winHandler *window.WindowHandler) {
 		PomodoroMode:    "Ready",
 		WorkMinutes:     25,
 		BreakMinutes:    5,
+		FlowRatio:       5,
 		CustomWebsites:  []string{},
 		BackgroundImage: ui.LoadBackgroundImage(),
+		Stats:           history.Compute(store.Sessions(), time.Now(), 5),
+		TaskEstimate:    1,
+	}
+
+	syncTasks := func() {
+		state.Tasks = taskList.All()
+		state.SelectedTask = taskList.Selected()
+		state.CurrentTask = ""
+		if t, ok := taskList.Current(); ok {
+			state.CurrentTask = fmt.Sprintf("Task: %s (%d/%d)", t.Title, t.Actual, t.Estimate)
+		}
+	}
+	syncTasks()
+
+	soundSettings, _ := sound.LoadSettings(appdir.File("sound.json"))
+	sound.Use(soundSettings)
+	settingsBtns.Volume.Value = float32(soundSettings.Volume())
+	// packLabel names the active pack, or "Default" without one
+	packLabel := func(id string) string {
+		for _, p := range sound.LoadPacks() {
+			if p.ID == id {
+				return p.Name
+			}
+		}
+		return "Default"
+	}
+	state.SoundPack = packLabel(soundSettings.Pack())
+	state.Ambient, state.Tick = soundSettings.Ambient()
+	state.AlarmSilence = int(soundSettings.AlarmSilence().Minutes())
+	state.SoundEnabled = make(map[sound.Event]bool)
+	for _, e := range sound.Events {
+		es := soundSettings.Event(e)
+		state.SoundEnabled[e] = es.Enabled
+		settingsBtns.SoundFile[e].SetText(es.File)
+	}
+
+	speechSettings, _ := speech.LoadSettings(appdir.File("speech.json"))
+	announcer := speech.NewAnnouncer(speech.DefaultSpeaker(), speechSettings)
+	state.Announce = make(map[speech.Event]bool)
+	for _, e := range speech.Events {
+		state.Announce[e] = speechSettings.Enabled(e)
+	}
+
+	notifier := notify.Default()
+	state.WarnMinutes = map[int]bool{5: true, 1: true}
+	applyWarnings := func() {
+		var at []time.Duration
+		for _, m := range ui.WarnPresets {
+			if state.WarnMinutes[m] {
+				at = append(at, time.Duration(m)*time.Minute)
+			}
+		}
+		pomoTimer.SetWarnAt(at)
 	}
+	applyWarnings()
 
 	alarmPlayer := sound.NewAlarmPlayer()
 
 	var isBlocking atomic.Bool
 	isBlocking.Store(false)
 
+	var lastSnapshot time.Time
+	saveSnapshot := func() {
+		lastSnapshot = time.Now()
+		st := pomoTimer.State()
+		if st.Mode == pomodoro.IdleMode || st.Mode == pomodoro.WorkAlarmMode || st.Mode == pomodoro.BreakAlarmMode {
+			_ = recovery.Clear(snapshotPath)
+			return
+		}
+		snap := recovery.Snapshot{
+			Mode:         st.Mode,
+			PreviousMode: st.PreviousMode,
+			Deadline:     time.Now().Add(st.Remaining),
+			Remaining:    st.Remaining,
+			Elapsed:      st.Elapsed,
+			Flowtime:     pomoTimer.Flowtime,
+			Cycle:        st.Cycle,
+			Sites:        b.Sites,
+		}
+		if t, ok := taskList.Current(); ok {
+			snap.Task, snap.TaskID = t.Title, t.ID
+		}
+		if sess, ok := recorder.Current(); ok {
+			snap.Session = &sess
+			snap.PausedAt = recorder.PausedAt()
+		}
+		_ = recovery.Save(snapshotPath, snap)
+	}
+
+	statusNow := func() control.Status {
+		task := ""
+		if t, ok := taskList.Current(); ok {
+			task = t.Title
+		}
+		return timerStatus(pomoTimer, isBlocking.Load(), b.Sites, task)
+	}
+
+	var apiServer atomic.Pointer[api.Server]
+	// publish sends the status to the tray and to API subscribers
+	publish := func(kind string) {
+		tray.SetStatus(trayStatus(statusNow()))
+		if srv := apiServer.Load(); srv != nil {
+			srv.Publish(api.Event{Type: kind, Status: statusNow()})
+		}
+	}
+
+	setProgress := func() {
+		state.Progress = float32(pomoTimer.Progress())
+		state.ProgressRate = float32(pomoTimer.ProgressRate())
+		state.ProgressAt = time.Now()
+	}
+
+	// transition runs after every mode change
+	transition := func() {
+		setProgress()
+		state.TimerMode = pomoTimer.Mode()
+		saveSnapshot()
+		publish(api.ModeEvent)
+	}
+
+	// offer to pick up a session that was cut short by a crash
+	pending, hasPending := recovery.Load(snapshotPath)
+	if hasPending {
+		shown := pending.Remaining
+		if pending.Flowtime && (pending.Mode == pomodoro.WorkMode || pending.PreviousMode == pomodoro.WorkMode) {
+			shown = pending.Elapsed
+		}
+		state.ResumePrompt = fmt.Sprintf("Resume %s (%s)?", modeLabel(pending.Mode, pending.Flowtime), formatClock(shown))
+	} else {
+		_ = recovery.Clear(snapshotPath)
+	}
+
+	logInterruption := func(kind string) {
+		if !recorder.Active() {
+			return
+		}
+		recorder.Interrupt(kind, strings.TrimSpace(btns.Note.Text()))
+		btns.Note.SetText("")
+		if kind == history.InternalInterruption {
+			state.InternalCount++
+		} else {
+			state.ExternalCount++
+		}
+	}
+
+	finishSession := func(completed bool) history.Session {
+		sess, _ := recorder.Finish(completed)
+		if completed && sess.TaskID != 0 {
+			_ = taskList.CountPomodoro(sess.TaskID)
+			syncTasks()
+		}
+		state.Stats = history.Compute(store.Sessions(), time.Now(), 5)
+		w.Invalidate()
+		return sess
+	}
+
+	// ringAlarm escalates the alarm for ev; if it silences itself the
+	// finished session, if any, is marked as never acknowledged
+	ringAlarm := func(ev sound.Event, sess history.Session) {
+		path, ok := sound.EventPath(ev)
+		if !ok {
+			return
+		}
+		sounds := []string{path}
+		if alt, ok := sound.EventPath(sound.Complete); ok && alt != path {
+			sounds = append(sounds, alt)
+		}
+		alarmPlayer.SetSilenceAfter(soundSettings.AlarmSilence())
+		alarmPlayer.PlayEscalating(sounds, func() {
+			if sess.Start.IsZero() {
+				return
+			}
+			sess.Unacknowledged = true
+			_ = store.Update(sess)
+			w.Invalidate()
+		})
+	}
+
+	// phase notifications; their buttons come back on phaseActions
+	phaseActions := make(chan string)
+	notifyPhase := func(mode pomodoro.Mode) {
+		n := phaseNotification(mode, state.BreakMinutes, func(id string) { phaseActions <- id })
+		go func() {
+			_ = notifier.Notify(n)
+		}()
+	}
+
+	// uiMu serializes the frame loop with the timer updates and with
+	// commands from other processes; everything that touches state or
+	// b.Sites holds it
+	var uiMu sync.Mutex
+
 	go func() {
 		var lastMode pomodoro.Mode = pomodoro.IdleMode
 
 		for remaining := range pomoTimer.Updates {
-			minutes := int(remaining.Minutes())
-			seconds := int(remaining.Seconds()) % 60
-			state.PomodoroTime = fmt.Sprintf("%02d:%02d", minutes, seconds)
+			uiMu.Lock()
+			state.PomodoroTime = formatClock(remaining)
 
-			currentMode := pomoTimer.Mode
+			currentMode := pomoTimer.Mode()
 
 			// check for mode changes to handle blocking
 			if currentMode != lastMode {
+				// background noise only plays while working
+				if currentMode == pomodoro.WorkMode {
+					sound.StartAmbient()
+				} else {
+					sound.StopAmbient()
+				}
+				switch {
+				case currentMode == pomodoro.WorkMode && lastMode != pomodoro.PauseMode:
+					minutes := state.WorkMinutes
+					if pomoTimer.Flowtime {
+						minutes = 0
+					}
+					announcer.Announce(speech.WorkStart, minutes)
+				case currentMode == pomodoro.WorkAlarmMode:
+					announcer.Announce(speech.WorkOver, state.BreakMinutes)
+				case currentMode == pomodoro.BreakMode && lastMode == pomodoro.WorkMode:
+					// flowtime goes straight from work to its break
+					announcer.Announce(speech.WorkOver, int(remaining.Round(time.Minute).Minutes()))
+				case currentMode == pomodoro.BreakAlarmMode:
+					announcer.Announce(speech.BreakOver, 0)
+				}
+
 				if currentMode == pomodoro.WorkMode && !isBlocking.Load() {
 					isBlocking.Store(true)
 					updateBlocker(&b, state)
@@ -181,41 +558,264 @@ func runApp(winHandler *window.WindowHandler) {
 						_ = b.RemoveBlockEntries()
 					}()
 				} else if currentMode == pomodoro.WorkAlarmMode {
-					alarmPlayer.PlayRepeating(sound.GetSoundPath("work_alarm.mp3"))
+					sess := finishSession(true)
+					ringAlarm(sound.WorkAlarm, sess)
+					notifyPhase(currentMode)
 				} else if currentMode == pomodoro.BreakAlarmMode {
-					alarmPlayer.PlayRepeating(sound.GetSoundPath("break_alarm.mp3"))
+					ringAlarm(sound.BreakAlarm, history.Session{})
+					notifyPhase(currentMode)
 				}
 				lastMode = currentMode
+				transition()
+			} else if time.Since(lastSnapshot) >= snapshotEvery {
+				// a crash loses at most this much focus time
+				saveSnapshot()
 			}
 
-			switch currentMode {
-			case pomodoro.WorkMode:
-				state.PomodoroMode = "Work Time"
-			case pomodoro.BreakMode:
-				state.PomodoroMode = "Break Time"
-			case pomodoro.PauseMode:
-				state.PomodoroMode = "Paused"
-			case pomodoro.IdleMode:
-				state.PomodoroMode = "Ready"
-			case pomodoro.WorkAlarmMode:
-				state.PomodoroMode = "Work Complete - Press Start for Break"
-			case pomodoro.BreakAlarmMode:
-				state.PomodoroMode = "Break Complete - Press Start for Work"
+			state.PomodoroMode = modeLabel(currentMode, pomoTimer.Flowtime)
+			state.FlowActive = pomoTimer.Flowtime && currentMode == pomodoro.WorkMode
+			state.Working = recorder.Active()
+			setProgress()
+			state.TimerMode = currentMode
+			publish(api.TickEvent)
+			uiMu.Unlock()
+			w.Invalidate()
+			invalidateMini()
+		}
+	}()
+
+	pressStart := func() {
+		state.ResumePrompt = ""
+		updateBlocker(&b, state)
+		mode := pomoTimer.Mode()
+		if mode == pomodoro.PauseMode {
+			recorder.Resume()
+			pomoTimer.Resume()
+		} else if mode == pomodoro.WorkMode && pomoTimer.Flowtime {
+			finishSession(true)
+			pomoTimer.FinishFlow()
+		} else if mode == pomodoro.IdleMode {
+			go func() {
+				_ = b.AddBlockEntries()
+			}()
+			beginSession()
+			state.InternalCount, state.ExternalCount = 0, 0
+			pomoTimer.Start()
+		} else if mode == pomodoro.WorkAlarmMode {
+			alarmPlayer.Stop()
+			pomoTimer.StartBreak()
+		} else if mode == pomodoro.BreakAlarmMode {
+			alarmPlayer.Stop()
+			pomoTimer.Stop()
+			state.PomodoroTime = idleTime(state)
+			state.PomodoroMode = "Ready"
+		}
+		transition()
+	}
+	pressPause := func() {
+		pomoTimer.Pause()
+		recorder.Pause()
+		transition()
+	}
+	pressReset := func() {
+		alarmPlayer.Stop()
+		sound.StopAmbient()
+		finishSession(false)
+		pomoTimer.Stop()
+		updateBlocker(&b, state)
+		state.PomodoroTime = idleTime(state)
+		state.PomodoroMode = "Ready"
+		transition()
+	}
+
+	// the mini timer is a small frameless window that stays on top and
+	// reopens where it was last dragged to
+	placementPath := appdir.File("mini.json")
+	var miniHwnd atomic.Uintptr
+	saveMiniPlacement := func() {
+		h := miniHwnd.Load()
+		if h == 0 {
+			return
+		}
+		if x, y, err := winHandler.Position(h); err == nil {
+			_ = window.SavePlacement(placementPath, window.Placement{X: x, Y: y})
+		}
+	}
+	openMini := func() {
+		mw := new(app.Window)
+		mw.Option(
+			app.Size(unit.Dp(150), unit.Dp(64)),
+			app.Title("Nuisance mini"),
+			app.Decorated(false),
+		)
+		miniWin.Store(mw)
+		state.MiniOpen = true
+
+		go func() {
+			time.Sleep(300 * time.Millisecond)
+			for range 50 {
+				if miniWin.Load() != mw {
+					return
+				}
+				h := winHandler.FindWindowByTitle("Nuisance mini")
+				if h != 0 {
+					miniHwnd.Store(h)
+					_ = winHandler.SetAlwaysOnTop(h, true)
+					if p, ok := window.LoadPlacement(placementPath); ok {
+						_ = winHandler.Move(h, p.X, p.Y)
+					}
+					return
+				}
+				time.Sleep(100 * time.Millisecond)
 			}
+		}()
+
+		go func() {
+			var ops op.Ops
+			for {
+				switch e := mw.Event().(type) {
+				case app.DestroyEvent:
+					uiMu.Lock()
+					if miniWin.CompareAndSwap(mw, nil) {
+						miniHwnd.Store(0)
+						state.MiniOpen = false
+					}
+					uiMu.Unlock()
+					w.Invalidate()
+					return
+				case app.FrameEvent:
+					uiMu.Lock()
+					gtx := app.NewContext(&ops, e)
+					ui.MiniLayout(gtx, th, state)
+					e.Frame(gtx.Ops)
+					uiMu.Unlock()
+				}
+			}
+		}()
+	}
+	closeMini := func() {
+		mw := miniWin.Swap(nil)
+		if mw == nil {
+			return
+		}
+		saveMiniPlacement()
+		miniHwnd.Store(0)
+		state.MiniOpen = false
+		// not under uiMu: the window may be waiting for it to draw
+		go mw.Perform(system.ActionClose)
+	}
+
+	// remaining-time warnings: soft chime, notification, spoken warning
+	// and a pulse on the timer
+	go func() {
+		for warn := range pomoTimer.Warnings {
+			minutes := int(warn.Remaining / time.Minute)
+			phase := "work"
+			if warn.Mode == pomodoro.BreakMode {
+				phase = "break"
+			}
+			sound.Play(sound.Warning)
+			announcer.Announce(speech.Warning, minutes)
+			go func() {
+				_ = notifier.Notify(notify.Notification{
+					Title: speech.Message(speech.Warning, minutes),
+					Body:  fmt.Sprintf("%s of %s left.", formatClock(warn.Remaining), phase),
+				})
+			}()
+			uiMu.Lock()
+			state.PulseUntil = time.Now().Add(3 * time.Second)
+			publish(api.WarningEvent)
+			uiMu.Unlock()
 			w.Invalidate()
+			invalidateMini()
 		}
 	}()
 
+	ctrl := &appController{
+		mu: &uiMu,
+		start: func() {
+			switch pomoTimer.Mode() {
+			case pomodoro.IdleMode, pomodoro.PauseMode, pomodoro.WorkAlarmMode, pomodoro.BreakAlarmMode:
+				pressStart()
+			}
+		},
+		pause: pressPause,
+		resume: func() {
+			if pomoTimer.Mode() == pomodoro.PauseMode {
+				pressStart()
+			}
+		},
+		reset: pressReset,
+		show: func() {
+			_ = winHandler.SetVisible(hwnd.Load(), true)
+			tray.SetWindowVisible(true)
+			w.Perform(system.ActionRaise)
+			_ = winHandler.Focus(hwnd.Load())
+		},
+		status:     statusNow,
+		invalidate: w.Invalidate,
+	}
+	go func() {
+		for id := range phaseActions {
+			_ = startPhase(id, func(cmd string) (control.Status, error) {
+				return control.Run(ctrl, cmd)
+			})
+		}
+	}()
+	tray.Run(tray.Menu{
+		Start: func() { _ = ctrl.Start() },
+		Pause: func() { _ = ctrl.Pause() },
+		Reset: func() { _ = ctrl.Reset() },
+		ShowWindow: func(show bool) {
+			if show {
+				_ = ctrl.Show()
+			} else {
+				_ = winHandler.SetVisible(hwnd.Load(), false)
+			}
+		},
+		Quit: func() {
+			cleanupOnce.Do(cleanup)
+			w.Perform(system.ActionClose)
+		},
+	})
+	tray.SetStatus(trayStatus(ctrl.Status()))
+	if srv, err := ipc.Listen(ipc.SocketPath(), ctrl); err == nil {
+		defer srv.Close()
+	}
+	if opts.start {
+		_ = ctrl.Start()
+	}
+	if opts.apiAddr != "" {
+		token := opts.apiToken
+		if token == "" {
+			var err error
+			if token, err = api.LoadToken(appdir.File("api.token")); err != nil {
+				fmt.Fprintf(os.Stderr, "nuisance: api token: %v\n", err)
+			}
+		}
+		srv := api.New(opts.apiAddr, token, opts.apiOrigin, ctrl)
+		if err := srv.Start(); err != nil {
+			fmt.Fprintf(os.Stderr, "nuisance: api server not started: %v\n", err)
+		} else {
+			apiServer.Store(srv)
+			defer srv.Close()
+		}
+	}
+
 	var ops op.Ops
 
 	for {
 		e := w.Event()
 		switch e := e.(type) {
 		case app.DestroyEvent:
+			uiMu.Lock()
+			closeMini()
+			uiMu.Unlock()
 			cleanupOnce.Do(cleanup)
 			return
 
 		case app.FrameEvent:
+			uiMu.Lock()
 			gtx := app.NewContext(&ops, e)
 
 			if btns.Tab1.Clicked(gtx) {
@@ -226,7 +826,17 @@ func runApp(winHandler *window.WindowHandler) {
 			if btns.Tab2.Clicked(gtx) {
 				sound.PlayButton()
 				state.CurrentTab = 1
-				w.Option(app.Size(unit.Dp(360), unit.Dp(700)))
+				w.Option(app.Size(unit.Dp(360), unit.Dp(760)))
+			}
+			if btns.Tab3.Clicked(gtx) {
+				sound.PlayButton()
+				state.CurrentTab = 2
+				w.Option(app.Size(unit.Dp(360), unit.Dp(520)))
+			}
+			if btns.Tab4.Clicked(gtx) {
+				sound.PlayButton()
+				state.CurrentTab = 3
+				w.Option(app.Size(unit.Dp(360), unit.Dp(460)))
 			}
 
 			if btns.Toggle.Clicked(gtx) {
@@ -242,38 +852,100 @@ func runApp(winHandler *window.WindowHandler) {
 				}
 			}
 
+			if btns.Mini.Clicked(gtx) {
+				sound.PlayButton()
+				if state.MiniOpen {
+					closeMini()
+				} else {
+					openMini()
+				}
+			}
+
+			if btns.ExportScope.Clicked(gtx) {
+				sound.PlayButton()
+				state.ExportScope = (state.ExportScope + 1) % len(ui.ExportScopes)
+				state.ExportStatus = ""
+			}
+			if btns.ExportCSV.Clicked(gtx) {
+				sound.PlayButton()
+				state.ExportStatus = exportSessions(store, state.ExportScope, "csv")
+			}
+			if btns.ExportJSON.Clicked(gtx) {
+				sound.PlayButton()
+				state.ExportStatus = exportSessions(store, state.ExportScope, "json")
+			}
+
+			if btns.ResumeYes.Clicked(gtx) {
+				sound.PlayButton()
+				state.ResumePrompt = ""
+				if pomoTimer.Mode() == pomodoro.IdleMode {
+					state.Flowtime = pending.Flowtime
+					pomoTimer.Flowtime = pending.Flowtime
+					if pending.Session != nil {
+						recorder.Restore(*pending.Session, pending.PausedAt, pending.SavedAt)
+					}
+					if i := taskList.Find(pending.TaskID); i >= 0 && taskList.Selected() != i {
+						_ = taskList.Select(i)
+						syncTasks()
+					}
+					// block the sites the session started with, not
+					// whatever the settings say now
+					if pending.Mode == pomodoro.WorkMode || pending.Mode == pomodoro.PauseMode && pending.PreviousMode == pomodoro.WorkMode {
+						isBlocking.Store(true)
+						b.Sites = pending.Sites
+						go func() {
+							_ = b.AddBlockEntries()
+						}()
+					}
+					pomoTimer.Restore(pending.Mode, pending.PreviousMode, pending.Remaining, pending.Elapsed, pending.Cycle)
+					state.PomodoroMode = modeLabel(pending.Mode, pending.Flowtime)
+					state.PomodoroTime = formatClock(pending.Remaining)
+					if pending.Flowtime && pending.PreviousMode == pomodoro.WorkMode {
+						state.PomodoroTime = formatClock(pending.Elapsed)
+					}
+					transition()
+				}
+			}
+			if btns.ResumeNo.Clicked(gtx) {
+				sound.PlayButton()
+				state.ResumePrompt = ""
+				_ = recovery.Clear(snapshotPath)
+			}
+
 			// handle Pomodoro button clicks
 			if btns.PomoPlay.Clicked(gtx) {
 				sound.PlayButton()
-				updateBlocker(&b, state)
-				if pomoTimer.Mode == pomodoro.PauseMode {
-					pomoTimer.Resume()
-				} else if pomoTimer.Mode == pomodoro.IdleMode {
-					go func() {
-						_ = b.AddBlockEntries()
-					}()
-					pomoTimer.Start()
-				} else if pomoTimer.Mode == pomodoro.WorkAlarmMode {
-					alarmPlayer.Stop()
-					pomoTimer.StartBreak()
-				} else if pomoTimer.Mode == pomodoro.BreakAlarmMode {
-					alarmPlayer.Stop()
-					pomoTimer.Stop()
-					state.PomodoroTime = fmt.Sprintf("%02d:00", state.WorkMinutes)
-					state.PomodoroMode = "Ready"
+				pressStart()
+			}
+			if btns.Internal.Clicked(gtx) {
+				logInterruption(history.InternalInterruption)
+			}
+			if btns.External.Clicked(gtx) {
+				logInterruption(history.ExternalInterruption)
+			}
+			for {
+				ev, ok := gtx.Event(
+					key.Filter{Name: "I", Required: key.ModShortcut},
+					key.Filter{Name: "E", Required: key.ModShortcut},
+				)
+				if !ok {
+					break
+				}
+				if ke, ok := ev.(key.Event); ok && ke.State == key.Press {
+					if ke.Name == "I" {
+						logInterruption(history.InternalInterruption)
+					} else {
+						logInterruption(history.ExternalInterruption)
+					}
 				}
 			}
 			if btns.PomoPause.Clicked(gtx) {
 				sound.PlayButton()
-				pomoTimer.Pause()
+				pressPause()
 			}
 			if btns.PomoReset.Clicked(gtx) {
 				sound.PlayButton()
-				alarmPlayer.Stop()
-				pomoTimer.Stop()
-				updateBlocker(&b, state)
-				state.PomodoroTime = fmt.Sprintf("%02d:00", state.WorkMinutes)
-				state.PomodoroMode = "Ready"
+				pressReset()
 			}
 
 			if settingsBtns.WorkInc.Clicked(gtx) {
@@ -281,8 +953,8 @@ func runApp(winHandler *window.WindowHandler) {
 				if state.WorkMinutes < 60 {
 					state.WorkMinutes += 5
 					pomoTimer.UpdateDurations(state.WorkMinutes, state.BreakMinutes)
-					if pomoTimer.Mode == pomodoro.IdleMode {
-						state.PomodoroTime = fmt.Sprintf("%02d:00", state.WorkMinutes)
+					if pomoTimer.Mode() == pomodoro.IdleMode {
+						state.PomodoroTime = idleTime(state)
 					}
 				}
 			}
@@ -291,8 +963,8 @@ func runApp(winHandler *window.WindowHandler) {
 				if state.WorkMinutes > 5 {
 					state.WorkMinutes -= 5
 					pomoTimer.UpdateDurations(state.WorkMinutes, state.BreakMinutes)
-					if pomoTimer.Mode == pomodoro.IdleMode {
-						state.PomodoroTime = fmt.Sprintf("%02d:00", state.WorkMinutes)
+					if pomoTimer.Mode() == pomodoro.IdleMode {
+						state.PomodoroTime = idleTime(state)
 					}
 				}
 			}
@@ -311,6 +983,128 @@ func runApp(winHandler *window.WindowHandler) {
 				}
 			}
 
+			if settingsBtns.FlowToggle.Clicked(gtx) {
+				sound.PlayButton()
+				if pomoTimer.Mode() == pomodoro.IdleMode {
+					state.Flowtime = !state.Flowtime
+					pomoTimer.Flowtime = state.Flowtime
+					state.PomodoroTime = idleTime(state)
+				}
+			}
+			if settingsBtns.RatioInc.Clicked(gtx) {
+				sound.PlayButton()
+				if state.FlowRatio < 10 {
+					state.FlowRatio++
+					pomoTimer.FlowRatio = state.FlowRatio
+				}
+			}
+			if settingsBtns.RatioDec.Clicked(gtx) {
+				sound.PlayButton()
+				if state.FlowRatio > 2 {
+					state.FlowRatio--
+					pomoTimer.FlowRatio = state.FlowRatio
+				}
+			}
+
+			settingsBtns.Volume.Update(gtx)
+			if v := settingsBtns.Volume.Value; !settingsBtns.Volume.Dragging() && v != float32(soundSettings.Volume()) {
+				_ = soundSettings.SetVolume(float64(v))
+				sound.PlayButton()
+			}
+			for _, e := range sound.Events {
+				if settingsBtns.SoundToggle[e].Clicked(gtx) {
+					state.SoundEnabled[e] = !state.SoundEnabled[e]
+					_ = soundSettings.SetEnabled(e, state.SoundEnabled[e])
+					sound.PlayButton()
+				}
+				for {
+					ev, ok := settingsBtns.SoundFile[e].Update(gtx)
+					if !ok {
+						break
+					}
+					if _, ok := ev.(widget.SubmitEvent); ok {
+						_ = soundSettings.SetFile(e, strings.TrimSpace(settingsBtns.SoundFile[e].Text()))
+						ringing := e == sound.WorkAlarm && pomoTimer.Mode() == pomodoro.WorkAlarmMode ||
+							e == sound.BreakAlarm && pomoTimer.Mode() == pomodoro.BreakAlarmMode
+						if path, ok := sound.EventPath(e); ok && ringing && alarmPlayer.Playing() {
+							// the alarm picks up the new file on its next ring
+							alarmPlayer.SetSounds([]string{path})
+						} else {
+							// preview the new choice
+							sound.Play(e)
+						}
+					}
+				}
+			}
+
+			if settingsBtns.SilenceInc.Clicked(gtx) {
+				sound.PlayButton()
+				if state.AlarmSilence < 30 {
+					state.AlarmSilence++
+					_ = soundSettings.SetAlarmSilence(state.AlarmSilence)
+				}
+			}
+			if settingsBtns.SilenceDec.Clicked(gtx) {
+				sound.PlayButton()
+				if state.AlarmSilence > 0 {
+					state.AlarmSilence--
+					_ = soundSettings.SetAlarmSilence(state.AlarmSilence)
+				}
+			}
+			for _, m := range ui.WarnPresets {
+				if settingsBtns.WarnToggle[m].Clicked(gtx) {
+					sound.PlayButton()
+					state.WarnMinutes[m] = !state.WarnMinutes[m]
+					applyWarnings()
+				}
+			}
+			for _, e := range speech.Events {
+				if settingsBtns.AnnounceToggle[e].Clicked(gtx) {
+					sound.PlayButton()
+					state.Announce[e] = !state.Announce[e]
+					_ = speechSettings.SetEnabled(e, state.Announce[e])
+				}
+			}
+			if settingsBtns.PackCycle.Clicked(gtx) {
+				// cycle Default -> each pack in name order -> Default
+				ids := []string{""}
+				for _, p := range sound.LoadPacks() {
+					ids = append(ids, p.ID)
+				}
+				next := 0
+				for i, id := range ids {
+					if id == soundSettings.Pack() {
+						next = (i + 1) % len(ids)
+					}
+				}
+				_ = soundSettings.SetPack(ids[next])
+				state.SoundPack = packLabel(ids[next])
+				settingsBtns.Volume.Value = float32(soundSettings.Volume())
+				sound.PlayButton()
+			}
+			if settingsBtns.AmbientCycle.Clicked(gtx) {
+				sound.PlayButton()
+				next := 0
+				for i, n := range sound.Noises {
+					if n == state.Ambient {
+						next = (i + 1) % len(sound.Noises)
+					}
+				}
+				state.Ambient = sound.Noises[next]
+				_ = soundSettings.SetAmbient(state.Ambient)
+				if pomoTimer.Mode() == pomodoro.WorkMode {
+					sound.StartAmbient()
+				}
+			}
+			if settingsBtns.TickToggle.Clicked(gtx) {
+				sound.PlayButton()
+				state.Tick = !state.Tick
+				_ = soundSettings.SetTick(state.Tick)
+				if pomoTimer.Mode() == pomodoro.WorkMode {
+					sound.StartAmbient()
+				}
+			}
+
 			if settingsBtns.BlockFacebook.Clicked(gtx) {
 				sound.PlayButton()
 				state.BlockedSites["facebook"] = !state.BlockedSites["facebook"]
@@ -372,8 +1166,50 @@ func runApp(winHandler *window.WindowHandler) {
 				}
 			}
 
-			ui.Layout(gtx, th, btns, settingsBtns, state)
+			if taskBtns.EstInc.Clicked(gtx) {
+				sound.PlayButton()
+				if state.TaskEstimate < 10 {
+					state.TaskEstimate++
+				}
+			}
+			if taskBtns.EstDec.Clicked(gtx) {
+				sound.PlayButton()
+				if state.TaskEstimate > 1 {
+					state.TaskEstimate--
+				}
+			}
+			if taskBtns.Add.Clicked(gtx) {
+				sound.PlayButton()
+				title := strings.TrimSpace(taskBtns.TitleEditor.Text())
+				if title != "" {
+					_ = taskList.Add(title, state.TaskEstimate)
+					taskBtns.TitleEditor.SetText("")
+					syncTasks()
+				}
+			}
+			for i := 0; i < len(state.Tasks) && i < len(taskBtns.Select); i++ {
+				if taskBtns.Select[i].Clicked(gtx) {
+					sound.PlayButton()
+					_ = taskList.Select(i)
+					syncTasks()
+				}
+				if taskBtns.Done[i].Clicked(gtx) {
+					sound.PlayButton()
+					_ = taskList.ToggleDone(i)
+					syncTasks()
+				}
+				if taskBtns.Remove[i].Clicked(gtx) {
+					sound.PlayButton()
+					_ = taskList.Remove(i)
+					syncTasks()
+					break
+				}
+			}
+
+			ui.Layout(gtx, th, btns, settingsBtns, taskBtns, state)
+			invalidateMini()
 
+			uiMu.Unlock()
 			e.Frame(gtx.Ops)
 		}
 	}
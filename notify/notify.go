@@ -0,0 +1,52 @@
+// Package notify shows native desktop notifications.
+package notify
+
+import "strings"
+
+// Action is a button on a notification.
+type Action struct {
+	ID    string
+	Label string
+}
+
+type Notification struct {
+	Title   string
+	Body    string
+	Actions []Action
+	// OnAction is called on its own goroutine with the ID of the button
+	// that was pressed. On Windows a button launches Scheme:<id> instead,
+	// which reaches the app as a new process; see ParseURI.
+	OnAction func(id string)
+}
+
+// Scheme is the URI scheme notification buttons use on Windows.
+const Scheme = "nuisance"
+
+// URI returns the link a button with the given action ID opens.
+func URI(id string) string {
+	return Scheme + ":" + id
+}
+
+// ParseURI returns the action ID of a link made by URI.
+func ParseURI(uri string) (string, bool) {
+	id, ok := strings.CutPrefix(uri, Scheme+":")
+	if !ok || id == "" {
+		return "", false
+	}
+	return strings.TrimSuffix(id, "/"), true
+}
+
+// Notifier shows notifications. Notify must not wait for the user.
+type Notifier interface {
+	Notify(n Notification) error
+}
+
+type NoopNotifier struct{}
+
+func (NoopNotifier) Notify(n Notification) error { return nil }
+
+// Default returns the platform notifier: the freedesktop D-Bus service
+// on Linux and toasts on Windows.
+func Default() Notifier {
+	return newNotifier()
+}
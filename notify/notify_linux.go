@@ -0,0 +1,115 @@
+package notify
+
+import (
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	busName    = "org.freedesktop.Notifications"
+	objectPath = "/org/freedesktop/Notifications"
+)
+
+// dbusNotifier talks to org.freedesktop.Notifications on the session
+// bus. Each notification replaces the previous one so they don't pile up.
+type dbusNotifier struct {
+	mu   sync.Mutex
+	dial func() (*dbus.Conn, error)
+	conn *dbus.Conn
+	last uint32
+	// OnAction of the notifications still on screen, by id
+	actions map[uint32]func(string)
+}
+
+func newNotifier() Notifier {
+	return NewDBus(func() (*dbus.Conn, error) { return dbus.ConnectSessionBus() })
+}
+
+// NewDBus returns a notifier that reaches the notification server
+// through the bus dial connects to, e.g. a private bus in tests.
+func NewDBus(dial func() (*dbus.Conn, error)) Notifier {
+	return &dbusNotifier{dial: dial, actions: make(map[uint32]func(string))}
+}
+
+func (d *dbusNotifier) connect() (*dbus.Conn, error) {
+	if d.conn != nil && d.conn.Connected() {
+		return d.conn, nil
+	}
+	conn, err := d.dial()
+	if err != nil {
+		return nil, err
+	}
+	// button presses come back as signals
+	_ = conn.AddMatchSignal(dbus.WithMatchInterface(busName), dbus.WithMatchObjectPath(objectPath))
+	signals := make(chan *dbus.Signal, 8)
+	conn.Signal(signals)
+	go d.listen(signals)
+
+	d.conn = conn
+	clear(d.actions)
+	return conn, nil
+}
+
+func (d *dbusNotifier) listen(signals <-chan *dbus.Signal) {
+	for sig := range signals {
+		if len(sig.Body) < 2 {
+			continue
+		}
+		id, ok := sig.Body[0].(uint32)
+		if !ok {
+			continue
+		}
+		switch sig.Name {
+		case busName + ".ActionInvoked":
+			key, _ := sig.Body[1].(string)
+			d.mu.Lock()
+			onAction := d.actions[id]
+			d.mu.Unlock()
+			if onAction != nil && key != "" {
+				go onAction(key)
+			}
+		case busName + ".NotificationClosed":
+			d.mu.Lock()
+			delete(d.actions, id)
+			d.mu.Unlock()
+		}
+	}
+}
+
+func (d *dbusNotifier) Notify(n Notification) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	conn, err := d.connect()
+	if err != nil {
+		return err
+	}
+	// actions go over the bus as a flat list of id, label pairs
+	actions := []string{}
+	for _, a := range n.Actions {
+		actions = append(actions, a.ID, a.Label)
+	}
+	call := conn.Object(busName, objectPath).Call(busName+".Notify", 0,
+		"nuisance",                // app_name
+		d.last,                    // replaces_id
+		"",                        // app_icon
+		n.Title,                   // summary
+		n.Body,                    // body
+		actions,                   // actions
+		map[string]dbus.Variant{}, // hints
+		int32(-1),                 // expire_timeout: server default
+	)
+	if call.Err != nil {
+		return call.Err
+	}
+	if err := call.Store(&d.last); err != nil {
+		return err
+	}
+	if n.OnAction != nil && len(n.Actions) > 0 {
+		d.actions[d.last] = n.OnAction
+	} else {
+		delete(d.actions, d.last)
+	}
+	return nil
+}
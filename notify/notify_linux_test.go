@@ -0,0 +1,159 @@
+package notify
+
+import (
+	"bufio"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// fakeServer stands in for the desktop's notification daemon.
+type fakeServer struct {
+	mu      sync.Mutex
+	next    uint32
+	actions [][]string
+	replace []uint32
+}
+
+func (f *fakeServer) Notify(app string, replaces uint32, icon, summary, body string,
+	actions []string, hints map[string]dbus.Variant, timeout int32) (uint32, *dbus.Error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.next++
+	f.actions = append(f.actions, actions)
+	f.replace = append(f.replace, replaces)
+	return f.next, nil
+}
+
+func (f *fakeServer) calls() (actions [][]string, replace []uint32) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.actions), slices.Clone(f.replace)
+}
+
+// privateBus starts a dbus-daemon for the test and returns its address.
+func privateBus(t *testing.T) string {
+	t.Helper()
+	bin, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon not installed")
+	}
+	cmd := exec.Command(bin, "--session", "--nofork", "--print-address")
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Skip("dbus-daemon: ", err)
+	}
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+	addr, err := bufio.NewReader(out).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(addr)
+}
+
+func startServer(t *testing.T, addr string) (*fakeServer, *dbus.Conn) {
+	t.Helper()
+	conn, err := dbus.Connect(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	f := &fakeServer{}
+	if err := conn.Export(f, objectPath, busName); err != nil {
+		t.Fatal(err)
+	}
+	reply, err := conn.RequestName(busName, dbus.NameFlagDoNotQueue)
+	if err != nil || reply != dbus.RequestNameReplyPrimaryOwner {
+		t.Fatalf("RequestName: %v, %v", reply, err)
+	}
+	return f, conn
+}
+
+func newClient(t *testing.T, addr string) Notifier {
+	var conns []*dbus.Conn
+	t.Cleanup(func() {
+		for _, c := range conns {
+			_ = c.Close()
+		}
+	})
+	return NewDBus(func() (*dbus.Conn, error) {
+		c, err := dbus.Connect(addr)
+		if err == nil {
+			conns = append(conns, c)
+		}
+		return c, err
+	})
+}
+
+func TestDBusActionRoundTrip(t *testing.T) {
+	addr := privateBus(t)
+	server, conn := startServer(t, addr)
+	n := newClient(t, addr)
+
+	pressed := make(chan string, 1)
+	err := n.Notify(Notification{
+		Title:    "Work session complete",
+		Actions:  []Action{{ID: "start-break", Label: "Start break"}},
+		OnAction: func(id string) { pressed <- id },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	actions, _ := server.calls()
+	if want := []string{"start-break", "Start break"}; !slices.Equal(actions[0], want) {
+		t.Fatalf("actions on the bus = %q, want %q", actions[0], want)
+	}
+
+	if err := conn.Emit(objectPath, busName+".ActionInvoked", uint32(1), "start-break"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case id := <-pressed:
+		if id != "start-break" {
+			t.Fatalf("OnAction(%q), want start-break", id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("OnAction not called")
+	}
+}
+
+func TestDBusClosedNotificationForgetsAction(t *testing.T) {
+	addr := privateBus(t)
+	server, conn := startServer(t, addr)
+	n := newClient(t, addr)
+
+	pressed := make(chan string, 2)
+	onAction := func(id string) { pressed <- id }
+	_ = n.Notify(Notification{Actions: []Action{{ID: "start-work", Label: "Start work"}}, OnAction: onAction})
+	_ = conn.Emit(objectPath, busName+".NotificationClosed", uint32(1), uint32(2))
+	_ = conn.Emit(objectPath, busName+".ActionInvoked", uint32(1), "start-work")
+
+	// the second notification replaces the first
+	if err := n.Notify(Notification{Actions: []Action{{ID: "start-break", Label: "Start break"}}, OnAction: onAction}); err != nil {
+		t.Fatal(err)
+	}
+	if _, replace := server.calls(); replace[1] != 1 {
+		t.Fatalf("replaces_id = %d, want 1", replace[1])
+	}
+	_ = conn.Emit(objectPath, busName+".ActionInvoked", uint32(2), "start-break")
+
+	select {
+	case id := <-pressed:
+		if id != "start-break" {
+			t.Fatalf("closed notification's button ran OnAction(%q)", id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("OnAction not called")
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !windows && !linux
+
+package notify
+
+func newNotifier() Notifier {
+	return NoopNotifier{}
+}
@@ -0,0 +1,97 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+const createNoWindow = 0x08000000
+
+// toasts need a registered app id; borrowing PowerShell's means nothing
+// has to be installed
+const toastScript = `[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+[Windows.Data.Xml.Dom.XmlDocument, Windows.Data.Xml.Dom.XmlDocument, ContentType = WindowsRuntime] > $null
+$xml = New-Object Windows.Data.Xml.Dom.XmlDocument
+$xml.LoadXml([Console]::In.ReadToEnd())
+$toast = New-Object Windows.UI.Notifications.ToastNotification $xml
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe').Show($toast)`
+
+type toastNotifier struct {
+	bin      string
+	register sync.Once
+}
+
+func newNotifier() Notifier {
+	path, err := exec.LookPath("powershell.exe")
+	if err != nil {
+		return NoopNotifier{}
+	}
+	return &toastNotifier{bin: path}
+}
+
+func (t *toastNotifier) Notify(n Notification) error {
+	if len(n.Actions) > 0 {
+		// toast buttons can only open links, so the buttons open a
+		// nuisance: URI that starts this binary again
+		t.register.Do(func() { _ = registerScheme() })
+	}
+	cmd := exec.Command(t.bin, "-NoProfile", "-NonInteractive", "-Command", toastScript)
+	cmd.Stdin = bytes.NewReader(toastXML(n))
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true, CreationFlags: createNoWindow}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+func toastXML(n Notification) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(`<toast><visual><binding template="ToastGeneric"><text>`)
+	_ = xml.EscapeText(&buf, []byte(n.Title))
+	buf.WriteString(`</text><text>`)
+	_ = xml.EscapeText(&buf, []byte(n.Body))
+	buf.WriteString(`</text></binding></visual>`)
+	if len(n.Actions) > 0 {
+		buf.WriteString(`<actions>`)
+		for _, a := range n.Actions {
+			buf.WriteString(`<action activationType="protocol" content="`)
+			_ = xml.EscapeText(&buf, []byte(a.Label))
+			buf.WriteString(`" arguments="`)
+			_ = xml.EscapeText(&buf, []byte(URI(a.ID)))
+			buf.WriteString(`"/>`)
+		}
+		buf.WriteString(`</actions>`)
+	}
+	buf.WriteString(`</toast>`)
+	return buf.Bytes()
+}
+
+// registerScheme points nuisance: links at "nuisance.exe open-url <uri>"
+// for the current user.
+func registerScheme() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	root, _, err := registry.CreateKey(registry.CURRENT_USER, `Software\Classes\`+Scheme, registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+	_ = root.SetStringValue("", "URL:"+Scheme)
+	_ = root.SetStringValue("URL Protocol", "")
+
+	cmd, _, err := registry.CreateKey(root, `shell\open\command`, registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer cmd.Close()
+	return cmd.SetStringValue("", `"`+exe+`" open-url "%1"`)
+}
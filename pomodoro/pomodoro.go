@@ -1,6 +1,7 @@
 package pomodoro
 
 import (
+	"sync"
 	"time"
 )
 
@@ -15,35 +16,131 @@ const (
 	BreakAlarmMode
 )
 
+func (m Mode) String() string {
+	switch m {
+	case WorkMode:
+		return "work"
+	case BreakMode:
+		return "break"
+	case PauseMode:
+		return "paused"
+	case IdleMode:
+		return "idle"
+	case WorkAlarmMode:
+		return "work_alarm"
+	case BreakAlarmMode:
+		return "break_alarm"
+	}
+	return "unknown"
+}
+
+// Warning is sent on Warnings when a countdown passes one of WarnAt.
+type Warning struct {
+	Mode      Mode
+	Remaining time.Duration
+}
+
+// State is a consistent copy of the countdown, taken under the timer's
+// lock.
+type State struct {
+	Mode         Mode
+	PreviousMode Mode
+	Remaining    time.Duration
+	Elapsed      time.Duration
+	Cycle        int
+}
+
+// PomodoroTimer runs the countdown on its own goroutine. WorkDuration,
+// BreakDuration, Flowtime and FlowRatio are settings the caller owns;
+// the countdown goroutine never reads them, so they need no lock of
+// their own. The progress is read through State.
 type PomodoroTimer struct {
 	WorkDuration  time.Duration
 	BreakDuration time.Duration
-	Mode          Mode
-	Remaining     time.Duration
-	quit          chan struct{}
+	Flowtime      bool
+	FlowRatio     int
 	Updates       chan time.Duration
-	previousMode  Mode
+	Warnings      chan Warning
+
+	// mu guards the countdown, which run updates every second
+	mu           sync.Mutex
+	mode         Mode
+	previousMode Mode
+	remaining    time.Duration
+	elapsed      time.Duration
+	cycle        int
+	warnAt       []time.Duration
+	quit         chan struct{}
+
+	// Shutdown closes done and waits for runs to finish before it closes
+	// the channels, so run never sends on a closed channel
+	runMu  sync.Mutex
+	runs   sync.WaitGroup
+	done   chan struct{}
+	closed bool
 }
 
 func NewPomodoroTimer(workMinutes, breakMinutes int) *PomodoroTimer {
 	return &PomodoroTimer{
 		WorkDuration:  time.Duration(workMinutes) * time.Minute,
 		BreakDuration: time.Duration(breakMinutes) * time.Minute,
-		Mode:          IdleMode,
+		mode:          IdleMode,
+		FlowRatio:     5,
 		quit:          make(chan struct{}),
 		Updates:       make(chan time.Duration, 10),
+		Warnings:      make(chan Warning, 4),
+		done:          make(chan struct{}),
+	}
+}
+
+// State returns the countdown as it is now.
+func (pt *PomodoroTimer) State() State {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	return State{
+		Mode:         pt.mode,
+		PreviousMode: pt.previousMode,
+		Remaining:    pt.remaining,
+		Elapsed:      pt.elapsed,
+		Cycle:        pt.cycle,
 	}
 }
 
+func (pt *PomodoroTimer) Mode() Mode {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	return pt.mode
+}
+
+func (pt *PomodoroTimer) PreviousMode() Mode {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	return pt.previousMode
+}
+
+// SetWarnAt sets the remaining times that trigger a Warning in work and
+// break countdowns.
+func (pt *PomodoroTimer) SetWarnAt(at []time.Duration) {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	pt.warnAt = append([]time.Duration(nil), at...)
+}
+
 func (pt *PomodoroTimer) Start() {
-	if pt.Mode != IdleMode {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	if pt.mode != IdleMode {
 		return
 	}
 
-	pt.Mode = WorkMode
-	pt.Remaining = pt.WorkDuration
-	pt.quit = make(chan struct{})
-	go pt.run()
+	pt.mode = WorkMode
+	pt.remaining = pt.WorkDuration
+	pt.elapsed = 0
+	pt.cycle++
+	if pt.Flowtime {
+		pt.remaining = 0
+	}
+	pt.startRun()
 }
 
 func (pt *PomodoroTimer) UpdateDurations(workMinutes, breakMinutes int) {
@@ -52,13 +149,19 @@ func (pt *PomodoroTimer) UpdateDurations(workMinutes, breakMinutes int) {
 }
 
 func (pt *PomodoroTimer) Stop() {
-	if pt.Mode == IdleMode {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	if pt.mode == IdleMode {
 		return
 	}
 
-	pt.Mode = IdleMode
-	pt.Remaining = 0
+	pt.mode = IdleMode
+	pt.remaining = 0
+	pt.stopRun()
+}
 
+// stopRun ends the current countdown goroutine. pt.mu must be held.
+func (pt *PomodoroTimer) stopRun() {
 	select {
 	case <-pt.quit:
 	default:
@@ -66,91 +169,261 @@ func (pt *PomodoroTimer) Stop() {
 	}
 }
 
-func (pt *PomodoroTimer) run() {
+// startRun starts a new countdown goroutine unless the timer was shut
+// down. pt.mu must be held.
+func (pt *PomodoroTimer) startRun() {
+	pt.runMu.Lock()
+	defer pt.runMu.Unlock()
+	pt.quit = make(chan struct{})
+	if pt.closed {
+		return
+	}
+	pt.runs.Add(1)
+	go pt.run(pt.quit, pt.Flowtime)
+}
+
+func (pt *PomodoroTimer) run(quit chan struct{}, flowtime bool) {
+	defer pt.runs.Done()
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
 	for {
 		select {
 		case <-ticker.C:
-			pt.Remaining -= 1 * time.Second
-			select {
-			case pt.Updates <- pt.Remaining:
-			default:
+			if !pt.tick(quit, flowtime) {
+				return
 			}
-
-			if pt.Remaining <= 0 {
-				pt.switchMode()
-			}
-
-		case <-pt.quit:
+		case <-quit:
+			return
+		case <-pt.done:
 			return
 		}
 	}
 }
 
-func (pt *PomodoroTimer) Pause() {
-	if pt.Mode != WorkMode && pt.Mode != BreakMode {
-		return
+// tick moves the countdown on by a second and reports whether run should
+// keep going.
+func (pt *PomodoroTimer) tick(quit chan struct{}, flowtime bool) bool {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
+	// the countdown may have been stopped while this tick waited for mu
+	select {
+	case <-quit:
+		return false
+	default:
 	}
 
-	pt.previousMode = pt.Mode
-	pt.Mode = PauseMode
+	if pt.mode == WorkMode {
+		pt.elapsed += 1 * time.Second
+	}
 
+	// flowtime counts up until the user stops it
+	if flowtime && pt.mode == WorkMode {
+		pt.send(pt.elapsed)
+		return true
+	}
+
+	pt.remaining -= 1 * time.Second
+	pt.send(pt.remaining)
+	pt.checkWarnings()
+
+	if pt.remaining <= 0 {
+		pt.switchMode()
+		// let listeners see the alarm mode before the ticker stops
+		pt.send(pt.remaining)
+		return false
+	}
+	return true
+}
+
+func (pt *PomodoroTimer) send(d time.Duration) {
 	select {
-	case <-pt.quit:
+	case pt.Updates <- d:
 	default:
-		close(pt.quit)
 	}
 }
 
+// checkWarnings runs right after a tick; comparing against the previous
+// second also catches thresholds when a restored timer is off the grid.
+func (pt *PomodoroTimer) checkWarnings() {
+	prev := pt.remaining + time.Second
+	for _, at := range pt.warnAt {
+		if at > 0 && prev > at && pt.remaining <= at {
+			select {
+			case pt.Warnings <- Warning{Mode: pt.mode, Remaining: at}:
+			default:
+			}
+		}
+	}
+}
+
+func (pt *PomodoroTimer) Pause() {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	if pt.mode != WorkMode && pt.mode != BreakMode {
+		return
+	}
+
+	pt.previousMode = pt.mode
+	pt.mode = PauseMode
+	pt.stopRun()
+}
+
 func (pt *PomodoroTimer) Resume() {
-	if pt.Mode != PauseMode {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	if pt.mode != PauseMode {
 		return
 	}
 
-	pt.Mode = pt.previousMode
-	pt.quit = make(chan struct{})
-	go pt.run()
+	pt.mode = pt.previousMode
+	pt.startRun()
 }
 
 func (pt *PomodoroTimer) switchMode() {
-	if pt.Mode == WorkMode {
-		pt.Mode = WorkAlarmMode
-		pt.Remaining = 0
-		select {
-		case <-pt.quit:
-		default:
-			close(pt.quit)
+	if pt.mode == WorkMode {
+		pt.mode = WorkAlarmMode
+		pt.remaining = 0
+		pt.stopRun()
+	} else if pt.mode == BreakMode {
+		pt.mode = BreakAlarmMode
+		pt.remaining = 0
+		pt.stopRun()
+	}
+}
+
+func (pt *PomodoroTimer) StartBreak() {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	if pt.mode != WorkAlarmMode {
+		return
+	}
+	pt.mode = BreakMode
+	pt.remaining = pt.BreakDuration
+	pt.startRun()
+}
+
+func (pt *PomodoroTimer) FinishFlow() {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	if !pt.Flowtime {
+		return
+	}
+	if pt.mode != WorkMode && !(pt.mode == PauseMode && pt.previousMode == WorkMode) {
+		return
+	}
+
+	pt.stopRun()
+	pt.mode = BreakMode
+	pt.remaining = pt.flowBreak()
+	pt.startRun()
+}
+
+func (pt *PomodoroTimer) FlowBreak() time.Duration {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	return pt.flowBreak()
+}
+
+func (pt *PomodoroTimer) flowBreak() time.Duration {
+	ratio := pt.FlowRatio
+	if ratio < 1 {
+		ratio = 1
+	}
+	brk := (pt.elapsed / time.Duration(ratio)).Round(time.Second)
+	if brk < time.Minute {
+		brk = time.Minute
+	}
+	return brk
+}
+
+// Progress is how much of the current phase has passed, from 0 to 1.
+// Flowtime work has no end, so it is measured against WorkDuration.
+func (pt *PomodoroTimer) Progress() float64 {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	mode := pt.mode
+	if mode == PauseMode {
+		mode = pt.previousMode
+	}
+	var total, done time.Duration
+	switch mode {
+	case WorkMode:
+		total, done = pt.WorkDuration, pt.WorkDuration-pt.remaining
+		if pt.Flowtime {
+			done = pt.elapsed
 		}
-	} else if pt.Mode == BreakMode {
-		pt.Mode = BreakAlarmMode
-		pt.Remaining = 0
-		select {
-		case <-pt.quit:
-		default:
-			close(pt.quit)
+	case BreakMode:
+		total = pt.BreakDuration
+		if pt.Flowtime {
+			total = pt.flowBreak()
 		}
+		done = total - pt.remaining
+	case WorkAlarmMode, BreakAlarmMode:
+		return 1
+	default:
+		return 0
+	}
+	if total <= 0 {
+		return 0
 	}
+	return min(max(float64(done)/float64(total), 0), 1)
 }
 
-func (pt *PomodoroTimer) StartBreak() {
-	if pt.Mode != WorkAlarmMode {
+// ProgressRate is how much Progress grows per second while the countdown
+// runs, and 0 when it is stopped, paused or ringing.
+func (pt *PomodoroTimer) ProgressRate() float64 {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	var total time.Duration
+	switch pt.mode {
+	case WorkMode:
+		total = pt.WorkDuration
+	case BreakMode:
+		total = pt.BreakDuration
+		if pt.Flowtime {
+			total = pt.flowBreak()
+		}
+	}
+	if total <= 0 {
+		return 0
+	}
+	return float64(time.Second) / float64(total)
+}
+
+func (pt *PomodoroTimer) Restore(mode, previous Mode, remaining, elapsed time.Duration, cycle int) {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	if pt.mode != IdleMode {
 		return
 	}
-	pt.Mode = BreakMode
-	pt.Remaining = pt.BreakDuration
-	pt.quit = make(chan struct{})
-	go pt.run()
+
+	pt.mode = mode
+	pt.previousMode = previous
+	pt.remaining = remaining
+	pt.elapsed = elapsed
+	pt.cycle = cycle
+	if mode == WorkMode || mode == BreakMode {
+		pt.startRun()
+	}
 }
 
+// Shutdown stops the timer for good and closes Updates and Warnings once
+// the countdown goroutine has exited. It is safe to call more than once.
 func (pt *PomodoroTimer) Shutdown() {
-	if pt.Mode != IdleMode {
-		pt.Mode = IdleMode
-		select {
-		case <-pt.quit:
-		default:
-			close(pt.quit)
-		}
+	pt.runMu.Lock()
+	if pt.closed {
+		pt.runMu.Unlock()
+		return
 	}
+	pt.closed = true
+	close(pt.done)
+	pt.runMu.Unlock()
+
+	pt.runs.Wait()
+	pt.mu.Lock()
+	pt.mode = IdleMode
+	pt.mu.Unlock()
 	close(pt.Updates)
+	close(pt.Warnings)
 }
@@ -0,0 +1,117 @@
+package pomodoro
+
+import (
+	"testing"
+	"time"
+)
+
+func drain(t *testing.T, ch <-chan time.Duration) {
+	t.Helper()
+	timeout := time.After(3 * time.Second)
+	for {
+		select {
+		case _, ok := <-ch:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("Updates was not closed")
+		}
+	}
+}
+
+func TestShutdownAtPhaseEnd(t *testing.T) {
+	pt := NewPomodoroTimer(1, 1)
+	pt.WorkDuration = time.Second
+	pt.Start()
+
+	// the first update of the last second arrives just before switchMode
+	// sends the second one
+	select {
+	case <-pt.Updates:
+	case <-time.After(3 * time.Second):
+		t.Fatal("no update")
+	}
+	pt.Shutdown()
+	drain(t, pt.Updates)
+}
+
+func TestShutdownTwice(t *testing.T) {
+	pt := NewPomodoroTimer(1, 1)
+	pt.Start()
+	pt.Shutdown()
+	pt.Shutdown()
+	drain(t, pt.Updates)
+}
+
+func TestStartAfterShutdown(t *testing.T) {
+	pt := NewPomodoroTimer(1, 1)
+	pt.Shutdown()
+	pt.Start()
+	drain(t, pt.Updates)
+}
+
+func TestProgress(t *testing.T) {
+	pt := NewPomodoroTimer(10, 5)
+	if got := pt.Progress(); got != 0 {
+		t.Errorf("idle progress = %v, want 0", got)
+	}
+	pt.mode, pt.remaining = WorkMode, 5*time.Minute
+	if got := pt.Progress(); got != 0.5 {
+		t.Errorf("work progress = %v, want 0.5", got)
+	}
+	pt.previousMode, pt.mode = WorkMode, PauseMode
+	if got := pt.Progress(); got != 0.5 {
+		t.Errorf("paused progress = %v, want 0.5", got)
+	}
+	if got := pt.ProgressRate(); got != 0 {
+		t.Errorf("paused rate = %v, want 0", got)
+	}
+	pt.mode = BreakMode
+	if got := pt.ProgressRate(); got != 1.0/300 {
+		t.Errorf("break rate = %v, want 1/300", got)
+	}
+	pt.mode = BreakAlarmMode
+	if got := pt.Progress(); got != 1 {
+		t.Errorf("alarm progress = %v, want 1", got)
+	}
+}
+
+func TestStateWhileRunning(t *testing.T) {
+	pt := NewPomodoroTimer(1, 1)
+	pt.WorkDuration = 2 * time.Second
+	pt.SetWarnAt([]time.Duration{time.Second})
+	pt.Start()
+
+	// run with -race: State, Pause and Resume come from other goroutines
+	// while the countdown ticks
+	stop := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				_ = pt.State()
+				_ = pt.Progress()
+			}
+		}
+	}()
+	<-pt.Updates
+	pt.Pause()
+	if st := pt.State(); st.Mode != PauseMode || st.PreviousMode != WorkMode {
+		t.Errorf("after Pause: %+v", st)
+	}
+	pt.Resume()
+
+	deadline := time.After(5 * time.Second)
+	for pt.Mode() != WorkAlarmMode {
+		select {
+		case <-pt.Updates:
+		case <-deadline:
+			t.Fatalf("mode = %v, want work_alarm", pt.Mode())
+		}
+	}
+	close(stop)
+	pt.Shutdown()
+}
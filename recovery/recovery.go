@@ -0,0 +1,90 @@
+package recovery
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/catalinfl/nuisance/history"
+	"github.com/catalinfl/nuisance/pomodoro"
+)
+
+// paused and flowtime sessions have no deadline, so give up on them
+// after this long
+const maxAge = 12 * time.Hour
+
+// Snapshot is the timer state written to disk on every transition so a
+// session can be picked up again after a crash.
+type Snapshot struct {
+	Mode         pomodoro.Mode    `json:"mode"`
+	PreviousMode pomodoro.Mode    `json:"previous_mode"`
+	Deadline     time.Time        `json:"deadline"`
+	Remaining    time.Duration    `json:"remaining"`
+	Elapsed      time.Duration    `json:"elapsed"`
+	Flowtime     bool             `json:"flowtime"`
+	Cycle        int              `json:"cycle"`
+	Task         string           `json:"task,omitempty"`
+	TaskID       int              `json:"task_id,omitempty"`
+	Sites        []string         `json:"sites,omitempty"`
+	Session      *history.Session `json:"session,omitempty"`
+	PausedAt     time.Time        `json:"paused_at,omitzero"`
+	SavedAt      time.Time        `json:"saved_at"`
+}
+
+func Save(path string, snap Snapshot) error {
+	snap.SavedAt = time.Now()
+	output, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// write and rename so a crash never leaves a torn file behind
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, output, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func Clear(path string) error {
+	err := os.Remove(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// Load returns the saved snapshot if it can still be resumed, with
+// Remaining brought up to the deadline. Flowtime's Elapsed stays as
+// saved: the time the app was down is not focus time.
+func Load(path string) (Snapshot, bool) {
+	var snap Snapshot
+
+	input, err := os.ReadFile(path)
+	if err != nil {
+		return snap, false
+	}
+	if err := json.Unmarshal(input, &snap); err != nil {
+		return snap, false
+	}
+
+	now := time.Now()
+	switch snap.Mode {
+	case pomodoro.WorkMode, pomodoro.BreakMode:
+		if snap.Flowtime && snap.Mode == pomodoro.WorkMode {
+			return snap, now.Sub(snap.SavedAt) <= maxAge
+		}
+		if !now.Before(snap.Deadline) {
+			return snap, false
+		}
+		snap.Remaining = snap.Deadline.Sub(now).Round(time.Second)
+		return snap, true
+	case pomodoro.PauseMode:
+		// snapshots from before the pause start was saved
+		if snap.PausedAt.IsZero() {
+			snap.PausedAt = snap.SavedAt
+		}
+		return snap, now.Sub(snap.SavedAt) <= maxAge
+	}
+	return snap, false
+}
@@ -0,0 +1,85 @@
+package recovery
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/catalinfl/nuisance/pomodoro"
+)
+
+// write stores snap as if it was saved at savedAt.
+func write(t *testing.T, snap Snapshot, savedAt time.Time) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "session.json")
+	snap.SavedAt = savedAt
+	data, err := json.Marshal(snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFlowtimeDowntimeNotCounted(t *testing.T) {
+	path := write(t, Snapshot{
+		Mode:     pomodoro.WorkMode,
+		Flowtime: true,
+		Elapsed:  20 * time.Minute,
+	}, time.Now().Add(-time.Hour))
+
+	snap, ok := Load(path)
+	if !ok {
+		t.Fatal("snapshot not resumable")
+	}
+	if snap.Elapsed != 20*time.Minute {
+		t.Fatalf("elapsed = %v, want the saved 20m", snap.Elapsed)
+	}
+}
+
+func TestCountdownFollowsDeadline(t *testing.T) {
+	now := time.Now()
+	path := write(t, Snapshot{
+		Mode:      pomodoro.WorkMode,
+		Deadline:  now.Add(10 * time.Minute),
+		Remaining: 20 * time.Minute,
+	}, now.Add(-10*time.Minute))
+
+	snap, ok := Load(path)
+	if !ok {
+		t.Fatal("snapshot not resumable")
+	}
+	if d := snap.Remaining - 10*time.Minute; d < -time.Second || d > time.Second {
+		t.Fatalf("remaining = %v, want about 10m", snap.Remaining)
+	}
+}
+
+func TestPausedSnapshotKeepsPauseStart(t *testing.T) {
+	savedAt := time.Now().Add(-time.Hour)
+	path := write(t, Snapshot{Mode: pomodoro.PauseMode, PreviousMode: pomodoro.WorkMode}, savedAt)
+
+	snap, ok := Load(path)
+	if !ok {
+		t.Fatal("snapshot not resumable")
+	}
+	// older snapshots have no pause start; the save time stands in
+	if !snap.PausedAt.Equal(snap.SavedAt) {
+		t.Fatalf("paused at %v, want %v", snap.PausedAt, snap.SavedAt)
+	}
+}
+
+func TestExpired(t *testing.T) {
+	now := time.Now()
+	path := write(t, Snapshot{Mode: pomodoro.WorkMode, Deadline: now.Add(-time.Minute)}, now.Add(-30*time.Minute))
+	if _, ok := Load(path); ok {
+		t.Fatal("a pomodoro past its deadline was offered")
+	}
+	path = write(t, Snapshot{Mode: pomodoro.WorkMode, Flowtime: true}, now.Add(-13*time.Hour))
+	if _, ok := Load(path); ok {
+		t.Fatal("a flowtime session older than maxAge was offered")
+	}
+}
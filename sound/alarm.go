@@ -0,0 +1,202 @@
+package sound
+
+import (
+	"sync"
+	"time"
+)
+
+// Escalation controls how an alarm gets louder and more insistent the
+// longer it is ignored.
+type Escalation struct {
+	StartLevel  float64       // level of the first ring, relative to the volume
+	Step        float64       // added to the level on every ring, up to 1
+	Interval    time.Duration // gap before the second ring
+	MinInterval time.Duration
+	Shrink      float64 // the gap is multiplied by this after every ring
+}
+
+var DefaultEscalation = Escalation{
+	StartLevel:  0.3,
+	Step:        0.1,
+	Interval:    2 * time.Second,
+	MinInterval: 700 * time.Millisecond,
+	Shrink:      0.9,
+}
+
+// AlarmPlayer rings until stopped. It is safe to use from several
+// goroutines: every ring checks the alarm is still the current one
+// before and after starting its sound, so once Stop returns nothing
+// keeps playing.
+type AlarmPlayer struct {
+	// Escalation is read when an alarm starts.
+	Escalation Escalation
+
+	mu     sync.Mutex
+	player Player // nil means the package player
+	sounds []string
+	stop   chan struct{} // nil when not ringing
+	// an unanswered alarm stops on its own after this; 0 rings forever
+	silenceAfter time.Duration
+}
+
+func NewAlarmPlayer() *AlarmPlayer {
+	return &AlarmPlayer{Escalation: DefaultEscalation}
+}
+
+// NewAlarmPlayerWith rings through p instead of the shared player, e.g.
+// a recording fake in tests.
+func NewAlarmPlayerWith(p Player) *AlarmPlayer {
+	return &AlarmPlayer{Escalation: DefaultEscalation, player: p}
+}
+
+func (ap *AlarmPlayer) Playing() bool {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	return ap.stop != nil
+}
+
+// SetSilenceAfter applies from the next alarm.
+func (ap *AlarmPlayer) SetSilenceAfter(d time.Duration) {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	ap.silenceAfter = d
+}
+
+// SetSounds swaps the sounds of a ringing alarm from the next ring on,
+// keeping its current level.
+func (ap *AlarmPlayer) SetSounds(sounds []string) {
+	if len(sounds) == 0 {
+		return
+	}
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	ap.sounds = append([]string(nil), sounds...)
+}
+
+func (ap *AlarmPlayer) Stop() {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	ap.stopLocked()
+}
+
+func (ap *AlarmPlayer) stopLocked() {
+	if ap.stop == nil {
+		return
+	}
+	close(ap.stop)
+	ap.stop = nil
+	if ap.player != nil {
+		ap.player.Stop()
+	} else {
+		StopSound()
+	}
+}
+
+func (ap *AlarmPlayer) PlayRepeating(soundPath string) {
+	ap.PlayEscalating([]string{soundPath}, nil)
+}
+
+// PlayEscalating rings the first sound, getting louder and quicker each
+// time. Once it is at full level it takes turns through all of sounds.
+// If the silence timeout passes first, the alarm stops and onSilenced is
+// called. Calling it while already ringing just switches the sounds.
+func (ap *AlarmPlayer) PlayEscalating(sounds []string, onSilenced func()) {
+	if len(sounds) == 0 {
+		return
+	}
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+
+	ap.sounds = append([]string(nil), sounds...)
+	if ap.stop != nil {
+		return
+	}
+	stop := make(chan struct{})
+	ap.stop = stop
+
+	var silence <-chan time.Time
+	if ap.silenceAfter > 0 {
+		silence = time.After(ap.silenceAfter)
+	}
+	go ap.ring(stop, ap.Escalation, silence, onSilenced)
+}
+
+func (ap *AlarmPlayer) ring(stop chan struct{}, esc Escalation, silence <-chan time.Time, onSilenced func()) {
+	level, interval := esc.StartLevel, esc.Interval
+	rings := 0
+	for {
+		if !ap.ringOnce(stop, level, &rings) {
+			return
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-timer.C:
+		case <-silence:
+			timer.Stop()
+			if ap.silence(stop) && onSilenced != nil {
+				onSilenced()
+			}
+			return
+		case <-stop:
+			timer.Stop()
+			return
+		}
+
+		level = min(level+esc.Step, 1)
+		interval = max(time.Duration(float64(interval)*esc.Shrink), esc.MinInterval)
+	}
+}
+
+// ringOnce plays the next sound unless the alarm was stopped or
+// replaced in the meantime. The lock is not held while the sound is
+// decoded and started; if Stop ran during that, the sound is cut again.
+func (ap *AlarmPlayer) ringOnce(stop chan struct{}, level float64, rings *int) bool {
+	ap.mu.Lock()
+	if ap.stop != stop {
+		ap.mu.Unlock()
+		return false
+	}
+	sound := ap.sounds[0]
+	if level >= 1 {
+		sound = ap.sounds[*rings%len(ap.sounds)]
+		*rings++
+	}
+	p := ap.player
+	ap.mu.Unlock()
+
+	if sound == "" {
+		return true
+	}
+	if p != nil {
+		_ = playOn(p, sound, level)
+	} else {
+		_ = PlaySoundAt(sound, level)
+	}
+
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	if ap.stop == stop {
+		return true
+	}
+	// stopped while starting; a newer alarm plays over it anyway
+	if ap.stop == nil {
+		if p != nil {
+			p.Stop()
+		} else {
+			StopSound()
+		}
+	}
+	return false
+}
+
+// silence stops the alarm if it is still the one that timed out.
+func (ap *AlarmPlayer) silence(stop chan struct{}) bool {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	if ap.stop != stop {
+		return false
+	}
+	ap.stopLocked()
+	return true
+}
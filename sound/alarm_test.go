@@ -0,0 +1,154 @@
+package sound
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+var fastEscalation = Escalation{
+	StartLevel:  0.5,
+	Step:        0.25,
+	Interval:    4 * time.Millisecond,
+	MinInterval: time.Millisecond,
+	Shrink:      0.5,
+}
+
+func newTestAlarm() (*AlarmPlayer, *RecordingPlayer) {
+	rec := &RecordingPlayer{}
+	ap := NewAlarmPlayerWith(rec)
+	ap.Escalation = fastEscalation
+	return ap, rec
+}
+
+// waitPlays waits until rec has played at least n sounds.
+func waitPlays(t *testing.T, rec *RecordingPlayer, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for len(rec.Played()) < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("played %d sounds, want at least %d", len(rec.Played()), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestAlarmEscalates(t *testing.T) {
+	ap, rec := newTestAlarm()
+	ap.PlayEscalating([]string{"alarm", "complete"}, nil)
+	waitPlays(t, rec, 6)
+	ap.Stop()
+
+	played, levels := rec.Played(), rec.Levels()
+	want := []float64{0.5, 0.75, 1, 1}
+	for i, l := range want {
+		if levels[i] != l {
+			t.Errorf("ring %d level = %v, want %v", i, levels[i], l)
+		}
+	}
+	// quiet rings use the first sound, full-level rings take turns
+	wantSounds := []string{"alarm", "alarm", "alarm", "complete", "alarm", "complete"}
+	for i, s := range wantSounds {
+		if played[i] != s {
+			t.Errorf("ring %d played %q, want %q", i, played[i], s)
+		}
+	}
+}
+
+func TestAlarmStop(t *testing.T) {
+	ap, rec := newTestAlarm()
+	ap.PlayRepeating("alarm")
+	waitPlays(t, rec, 2)
+	ap.Stop()
+
+	if ap.Playing() {
+		t.Error("Playing after Stop")
+	}
+	if rec.Stops() == 0 {
+		t.Error("Stop did not stop the player")
+	}
+	// a ring that was already starting may land, but is cut right away
+	n, stops := len(rec.Played()), rec.Stops()
+	time.Sleep(30 * time.Millisecond)
+	switch got := len(rec.Played()); {
+	case got > n+1:
+		t.Errorf("%d more rings after Stop", got-n)
+	case got == n+1 && rec.Stops() == stops:
+		t.Error("a ring that landed after Stop kept playing")
+	}
+}
+
+func TestAlarmRestart(t *testing.T) {
+	ap, rec := newTestAlarm()
+	ap.PlayRepeating("first")
+	waitPlays(t, rec, 1)
+	ap.Stop()
+	n := len(rec.Played())
+
+	ap.PlayRepeating("second")
+	waitPlays(t, rec, n+3)
+	ap.Stop()
+	// one ring of the first alarm may still have been on its way
+	played := rec.Played()
+	for i := n + 1; i < len(played); i++ {
+		if played[i] != "second" {
+			t.Errorf("ring %d after restart played %q", i, played[i])
+		}
+	}
+}
+
+func TestAlarmSetSounds(t *testing.T) {
+	ap, rec := newTestAlarm()
+	ap.PlayRepeating("old")
+	waitPlays(t, rec, 1)
+	ap.SetSounds([]string{"new"})
+	n := len(rec.Played())
+	waitPlays(t, rec, n+2)
+	ap.Stop()
+	if got := rec.Played()[n+1]; got != "new" {
+		t.Errorf("ring after SetSounds played %q, want new", got)
+	}
+}
+
+func TestAlarmSilences(t *testing.T) {
+	ap, _ := newTestAlarm()
+	ap.SetSilenceAfter(20 * time.Millisecond)
+	silenced := make(chan struct{})
+	ap.PlayEscalating([]string{"alarm"}, func() { close(silenced) })
+	select {
+	case <-silenced:
+	case <-time.After(2 * time.Second):
+		t.Fatal("alarm was not silenced")
+	}
+	if ap.Playing() {
+		t.Error("Playing after being silenced")
+	}
+}
+
+func TestAlarmConcurrentUse(t *testing.T) {
+	ap, _ := newTestAlarm()
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				switch i % 4 {
+				case 0:
+					ap.PlayRepeating("alarm")
+				case 1:
+					ap.Stop()
+				case 2:
+					ap.SetSounds([]string{"other", "alarm"})
+				case 3:
+					_ = ap.Playing()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	ap.Stop()
+	if ap.Playing() {
+		t.Error("Playing after the final Stop")
+	}
+}
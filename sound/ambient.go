@@ -0,0 +1,230 @@
+package sound
+
+import (
+	"math"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+
+	"github.com/catalinfl/nuisance/sound/audio"
+)
+
+// Noise is a kind of synthesized background sound.
+type Noise string
+
+const (
+	NoNoise    Noise = ""
+	WhiteNoise Noise = "white"
+	PinkNoise  Noise = "pink"
+	BrownNoise Noise = "brown"
+	RainNoise  Noise = "rain"
+)
+
+// Noises lists the choices in the order Settings cycles through them.
+var Noises = []Noise{NoNoise, WhiteNoise, PinkNoise, BrownNoise, RainNoise}
+
+func (n Noise) Label() string {
+	switch n {
+	case WhiteNoise:
+		return "White noise"
+	case PinkNoise:
+		return "Pink noise"
+	case BrownNoise:
+		return "Brown noise"
+	case RainNoise:
+		return "Rain"
+	}
+	return "Off"
+}
+
+// ambient sounds sit well under the alarms
+const ambientLevel = 0.35
+
+const (
+	tickFreq     = 2000.0
+	tickDuration = audio.SampleRate * 4 / 1000
+)
+
+// Generator synthesizes endless noise, optionally with a clock tick once
+// a second. It is an audio.Source that never runs dry, so the output can
+// also be pulled into a plain slice.
+type Generator struct {
+	noise Noise
+	tick  bool
+	rng   *rand.Rand
+	gain  atomic.Uint32 // float32 bits
+	frame int64
+
+	pink  [7]float32
+	brown float32
+	rain  struct {
+		low, high, prev float32
+		drop            float32
+	}
+}
+
+// NewGenerator returns a generator at full scale; the same seed always
+// yields the same samples.
+func NewGenerator(noise Noise, tick bool, seed uint64) *Generator {
+	g := &Generator{
+		noise: noise,
+		tick:  tick,
+		rng:   rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15)),
+	}
+	g.SetVolume(1)
+	return g
+}
+
+func (g *Generator) SetVolume(v float64) {
+	g.gain.Store(math.Float32bits(float32(v)))
+}
+
+// Read fills p with interleaved stereo samples. It always fills all of p.
+func (g *Generator) Read(p []float32) (int, error) {
+	gain := math.Float32frombits(g.gain.Load())
+	frames := len(p) / audio.Channels
+	for i := 0; i < frames; i++ {
+		v := g.next()
+		if g.tick {
+			v += g.tickSample()
+		}
+		v = clampSample(v * gain)
+		p[2*i] = v
+		p[2*i+1] = v
+		g.frame++
+	}
+	return frames * audio.Channels, nil
+}
+
+func (g *Generator) white() float32 {
+	return g.rng.Float32()*2 - 1
+}
+
+func (g *Generator) next() float32 {
+	switch g.noise {
+	case WhiteNoise:
+		return g.white() * 0.5
+	case PinkNoise:
+		return g.pinkSample() * 0.5
+	case BrownNoise:
+		return g.brownSample()
+	case RainNoise:
+		return g.rainSample()
+	}
+	return 0
+}
+
+// Paul Kellet's refined pink noise filter.
+func (g *Generator) pinkSample() float32 {
+	w := g.white()
+	b := &g.pink
+	b[0] = 0.99886*b[0] + w*0.0555179
+	b[1] = 0.99332*b[1] + w*0.0750759
+	b[2] = 0.96900*b[2] + w*0.1538520
+	b[3] = 0.86650*b[3] + w*0.3104856
+	b[4] = 0.55000*b[4] + w*0.5329522
+	b[5] = -0.7616*b[5] - w*0.0168980
+	out := b[0] + b[1] + b[2] + b[3] + b[4] + b[5] + b[6] + w*0.5362
+	b[6] = w * 0.115926
+	return out * 0.11
+}
+
+// a leaky integrator over white noise
+func (g *Generator) brownSample() float32 {
+	g.brown = (g.brown + 0.02*g.white()) / 1.02
+	return g.brown * 2.5
+}
+
+// rain is band-passed pink noise for the hiss plus short random bursts
+// for the drops.
+func (g *Generator) rainSample() float32 {
+	r := &g.rain
+	x := g.pinkSample()
+	r.low += 0.25 * (x - r.low)
+	r.high = 0.97 * (r.high + r.low - r.prev)
+	r.prev = r.low
+	hiss := r.high * 1.5
+
+	if g.rng.Float32() < 0.0004 {
+		r.drop = 0.3 + 0.4*g.rng.Float32()
+	}
+	drop := r.drop * g.white()
+	r.drop *= 0.995
+	return hiss + drop
+}
+
+// a short decaying sine once a second
+func (g *Generator) tickSample() float32 {
+	pos := g.frame % audio.SampleRate
+	if pos >= tickDuration {
+		return 0
+	}
+	t := float64(pos) / audio.SampleRate
+	env := 1 - float64(pos)/tickDuration
+	return float32(0.3 * env * env * math.Sin(2*math.Pi*tickFreq*t))
+}
+
+func clampSample(v float32) float32 {
+	if v > 1 {
+		return 1
+	}
+	if v < -1 {
+		return -1
+	}
+	return v
+}
+
+// ambientPlayer streams one generator at a time to the platform backend.
+type ambientPlayer struct {
+	mu      sync.Mutex
+	backend audio.Backend
+	gen     *Generator
+	stop    chan struct{}
+}
+
+var ambient = &ambientPlayer{backend: newStreamBackend()}
+
+func (a *ambientPlayer) start(noise Noise, tick bool, volume float64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.stopLocked()
+	if a.backend == nil || (noise == NoNoise && !tick) {
+		return
+	}
+	gen := NewGenerator(noise, tick, rand.Uint64())
+	gen.SetVolume(volume * ambientLevel)
+	stop := make(chan struct{})
+	a.gen, a.stop = gen, stop
+	go func() {
+		_ = a.backend.Play(gen, stop)
+	}()
+}
+
+func (a *ambientPlayer) setVolume(v float64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.gen != nil {
+		a.gen.SetVolume(v * ambientLevel)
+	}
+}
+
+func (a *ambientPlayer) stopLocked() {
+	if a.stop != nil {
+		close(a.stop)
+		a.stop, a.gen = nil, nil
+	}
+}
+
+// StartAmbient starts the background sound chosen in the settings,
+// replacing any that is already playing.
+func StartAmbient() {
+	s := current()
+	noise, tick := s.Ambient()
+	ambient.start(noise, tick, s.Volume())
+}
+
+func StopAmbient() {
+	ambient.mu.Lock()
+	defer ambient.mu.Unlock()
+	ambient.stopLocked()
+}
@@ -0,0 +1,85 @@
+package sound
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/catalinfl/nuisance/sound/audio"
+)
+
+func readFrames(g *Generator, frames int) []float32 {
+	p := make([]float32, frames*audio.Channels)
+	n, _ := g.Read(p)
+	return p[:n]
+}
+
+func TestGeneratorFillsBuffer(t *testing.T) {
+	for _, noise := range Noises {
+		g := NewGenerator(noise, true, 1)
+		// an odd length leaves half a frame that Read must not count
+		p := make([]float32, 2*audio.SampleRate+1)
+		n, err := g.Read(p)
+		if err != nil {
+			t.Fatalf("%q: %v", noise, err)
+		}
+		if n != 2*audio.SampleRate {
+			t.Fatalf("%q: read %d samples, want %d", noise, n, 2*audio.SampleRate)
+		}
+	}
+}
+
+func TestGeneratorRange(t *testing.T) {
+	for _, noise := range Noises {
+		g := NewGenerator(noise, true, 7)
+		g.SetVolume(4) // push past full scale so clamping is exercised
+		p := readFrames(g, 5*audio.SampleRate)
+		for i, v := range p {
+			if v < -1 || v > 1 {
+				t.Fatalf("%q: sample %d = %v out of range", noise, i, v)
+			}
+		}
+		for i := 0; i < len(p); i += audio.Channels {
+			if p[i] != p[i+1] {
+				t.Fatalf("%q: frame %d channels differ", noise, i/audio.Channels)
+			}
+		}
+	}
+}
+
+func TestGeneratorDeterministic(t *testing.T) {
+	for _, noise := range Noises[1:] {
+		a := readFrames(NewGenerator(noise, false, 42), audio.SampleRate)
+		b := readFrames(NewGenerator(noise, false, 42), audio.SampleRate)
+		if !slices.Equal(a, b) {
+			t.Fatalf("%q: same seed gave different samples", noise)
+		}
+		c := readFrames(NewGenerator(noise, false, 43), audio.SampleRate)
+		if slices.Equal(a, c) {
+			t.Fatalf("%q: different seeds gave the same samples", noise)
+		}
+	}
+}
+
+func TestGeneratorSilentAtZeroVolume(t *testing.T) {
+	for _, noise := range Noises {
+		g := NewGenerator(noise, true, 3)
+		g.SetVolume(0)
+		for i, v := range readFrames(g, audio.SampleRate) {
+			if v != 0 {
+				t.Fatalf("%q: sample %d = %v at volume 0", noise, i, v)
+			}
+		}
+	}
+}
+
+func TestGeneratorNotSilent(t *testing.T) {
+	for _, noise := range Noises[1:] {
+		var peak float32
+		for _, v := range readFrames(NewGenerator(noise, false, 5), audio.SampleRate) {
+			peak = max(peak, v, -v)
+		}
+		if peak < 0.01 {
+			t.Fatalf("%q: peak %v, want audible noise", noise, peak)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+// Package audio decodes WAV, MP3 and OGG Vorbis files in Go and streams
+// the PCM to a platform backend.
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// Everything is converted to this format before it reaches a backend:
+// interleaved stereo float32 samples in [-1, 1].
+const (
+	SampleRate = 44100
+	Channels   = 2
+)
+
+var ErrUnknownFormat = errors.New("audio: unknown file format")
+
+// Source yields interleaved stereo samples at SampleRate and returns
+// io.EOF once it is exhausted.
+type Source interface {
+	Read(p []float32) (int, error)
+}
+
+// Backend plays a source until it ends or stop is closed.
+type Backend interface {
+	Play(src Source, stop <-chan struct{}) error
+}
+
+// Buffer holds a fully decoded sound.
+type Buffer struct {
+	Samples []float32
+}
+
+// Frames is the number of stereo sample pairs in the buffer.
+func (b *Buffer) Frames() int {
+	return len(b.Samples) / Channels
+}
+
+// Reader returns a new Source positioned at the start of the buffer.
+func (b *Buffer) Reader() Source {
+	return &bufferReader{samples: b.Samples}
+}
+
+type bufferReader struct {
+	samples []float32
+	pos     int
+}
+
+func (r *bufferReader) Read(p []float32) (int, error) {
+	if r.pos >= len(r.samples) {
+		return 0, io.EOF
+	}
+	n := copy(p, r.samples[r.pos:])
+	r.pos += n
+	return n, nil
+}
+
+// DecodeFile decodes the file at path, picking the decoder from its
+// leading bytes rather than the extension.
+func DecodeFile(path string) (*Buffer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Decode(f)
+}
+
+// Decode sniffs r and decodes it as WAV, OGG Vorbis or MP3.
+func Decode(r io.Reader) (*Buffer, error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(4)
+	switch {
+	case bytes.Equal(magic, []byte("RIFF")):
+		return decodeWAV(br)
+	case bytes.Equal(magic, []byte("OggS")):
+		return decodeOGG(br)
+	case len(magic) >= 3 && (bytes.Equal(magic[:3], []byte("ID3")) || magic[0] == 0xFF && magic[1]&0xE0 == 0xE0):
+		return decodeMP3(br)
+	}
+	return nil, ErrUnknownFormat
+}
+
+// Player decodes files on demand, caches the result and plays one sound
+// at a time through its backend.
+type Player struct {
+	backend Backend
+	volume  atomic.Uint32 // float32 bits
+
+	mu    sync.Mutex
+	stop  chan struct{}
+	cache map[string]*Buffer
+}
+
+func NewPlayer(backend Backend) *Player {
+	p := &Player{backend: backend, cache: make(map[string]*Buffer)}
+	p.SetVolume(1)
+	return p
+}
+
+// SetVolume scales everything played from now on, including sounds that
+// are already playing.
+func (p *Player) SetVolume(v float64) {
+	p.volume.Store(math.Float32bits(float32(v)))
+}
+
+// Play starts the file in the background, cutting off whatever was
+// playing. Decode errors are returned; playback errors are dropped.
+func (p *Player) Play(path string) error {
+	return p.PlayAt(path, 1)
+}
+
+// PlayAt is Play with the sound scaled by level on top of the volume.
+func (p *Player) PlayAt(path string, level float64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	buf, ok := p.cache[path]
+	if !ok {
+		var err error
+		buf, err = DecodeFile(path)
+		if err != nil {
+			return err
+		}
+		p.cache[path] = buf
+	}
+
+	p.stopLocked()
+	stop := make(chan struct{})
+	p.stop = stop
+	go func() {
+		_ = p.backend.Play(&gainSource{src: buf.Reader(), gain: &p.volume, level: float32(level)}, stop)
+	}()
+	return nil
+}
+
+func (p *Player) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stopLocked()
+}
+
+func (p *Player) stopLocked() {
+	if p.stop != nil {
+		close(p.stop)
+		p.stop = nil
+	}
+}
+
+type gainSource struct {
+	src   Source
+	gain  *atomic.Uint32
+	level float32
+}
+
+func (g *gainSource) Read(p []float32) (int, error) {
+	n, err := g.src.Read(p)
+	if gain := math.Float32frombits(g.gain.Load()) * g.level; gain != 1 {
+		for i := range p[:n] {
+			p[i] *= gain
+		}
+	}
+	return n, err
+}
+
+func clamp(v float32) float32 {
+	if v > 1 {
+		return 1
+	}
+	if v < -1 {
+		return -1
+	}
+	return v
+}
@@ -0,0 +1,34 @@
+package audio
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/hajimehoshi/go-mp3"
+	"github.com/jfreymuth/oggvorbis"
+)
+
+// go-mp3 always produces 16-bit little endian stereo.
+func decodeMP3(r io.Reader) (*Buffer, error) {
+	dec, err := mp3.NewDecoder(r)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(dec)
+	if err != nil {
+		return nil, err
+	}
+	samples := make([]float32, len(data)/2)
+	for i := range samples {
+		samples[i] = float32(int16(binary.LittleEndian.Uint16(data[2*i:]))) / 32768
+	}
+	return toStereo(samples, 2, dec.SampleRate()), nil
+}
+
+func decodeOGG(r io.Reader) (*Buffer, error) {
+	samples, format, err := oggvorbis.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return toStereo(samples, format.Channels, format.SampleRate), nil
+}
@@ -0,0 +1,101 @@
+package audio
+
+import (
+	"sync"
+
+	"github.com/jfreymuth/pulse"
+)
+
+// Pulse plays through PulseAudio, or PipeWire's pulse server. The
+// connection is made on first use so importing this package never
+// touches the sound server.
+type Pulse struct {
+	mu     sync.Mutex
+	client *pulse.Client
+}
+
+func NewPulse() *Pulse {
+	return &Pulse{}
+}
+
+func (p *Pulse) connect() (*pulse.Client, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.client != nil {
+		return p.client, nil
+	}
+	c, err := pulse.NewClient(pulse.ClientApplicationName("nuisance"))
+	if err != nil {
+		return nil, err
+	}
+	p.client = c
+	return c, nil
+}
+
+// Connect reports whether the sound server can be reached.
+func (p *Pulse) Connect() error {
+	_, err := p.connect()
+	return err
+}
+
+func (p *Pulse) Play(src Source, stop <-chan struct{}) error {
+	c, err := p.connect()
+	if err != nil {
+		return err
+	}
+
+	// the stream goes idle as soon as the reader errors, and Drain is a
+	// no-op on idle streams, so pad with silence and signal the end instead
+	ended := make(chan struct{})
+	var once sync.Once
+	reader := pulse.Float32Reader(func(buf []float32) (int, error) {
+		n, err := src.Read(buf)
+		if err != nil {
+			once.Do(func() { close(ended) })
+			clear(buf[n:])
+			return len(buf), nil
+		}
+		return n, nil
+	})
+
+	stream, err := c.NewPlayback(reader,
+		pulse.PlaybackStereo,
+		pulse.PlaybackSampleRate(SampleRate),
+		pulse.PlaybackLatency(0.1),
+		pulse.PlaybackMediaName("nuisance"),
+	)
+	if err != nil {
+		p.reset()
+		return err
+	}
+	defer stream.Close()
+
+	stream.Start()
+	select {
+	case <-ended:
+		drained := make(chan struct{})
+		go func() {
+			stream.Drain()
+			close(drained)
+		}()
+		select {
+		case <-drained:
+		case <-stop:
+			stream.Pause()
+		}
+	case <-stop:
+		stream.Pause()
+	}
+	return stream.Error()
+}
+
+// reset drops the client so the next Play reconnects, e.g. after the
+// sound server restarted.
+func (p *Pulse) reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.client != nil {
+		p.client.Close()
+		p.client = nil
+	}
+}
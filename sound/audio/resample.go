@@ -0,0 +1,45 @@
+package audio
+
+// toStereo converts interleaved samples with the given channel count and
+// rate into a Buffer at SampleRate. Extra channels beyond the first two
+// are dropped and mono is duplicated. Resampling is linear, which is
+// plenty for alarm chimes.
+func toStereo(samples []float32, channels, rate int) *Buffer {
+	if channels < 1 || rate < 1 {
+		return &Buffer{}
+	}
+	frames := len(samples) / channels
+	at := func(frame, ch int) float32 {
+		if ch >= channels {
+			ch = 0
+		}
+		return samples[frame*channels+ch]
+	}
+
+	if rate == SampleRate {
+		out := make([]float32, frames*Channels)
+		for i := 0; i < frames; i++ {
+			out[2*i] = at(i, 0)
+			out[2*i+1] = at(i, 1)
+		}
+		return &Buffer{Samples: out}
+	}
+
+	outFrames := int(int64(frames) * SampleRate / int64(rate))
+	out := make([]float32, outFrames*Channels)
+	step := float64(rate) / SampleRate
+	for i := 0; i < outFrames; i++ {
+		pos := float64(i) * step
+		j := int(pos)
+		frac := float32(pos - float64(j))
+		k := j + 1
+		if k >= frames {
+			k = frames - 1
+		}
+		for ch := 0; ch < Channels; ch++ {
+			a, b := at(j, ch), at(k, ch)
+			out[2*i+ch] = a + (b-a)*frac
+		}
+	}
+	return &Buffer{Samples: out}
+}
@@ -0,0 +1,65 @@
+package audio
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+// NullSink consumes sources as fast as it can and discards them. It
+// stands in for a sound card on machines without one.
+type NullSink struct{}
+
+func (NullSink) Play(src Source, stop <-chan struct{}) error {
+	_, err := drain(src, stop, nil)
+	return err
+}
+
+// FileSink writes every played source to a WAV file, replacing the file
+// each time. Handy for checking what the app would have played.
+type FileSink struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+func (s *FileSink) Play(src Source, stop <-chan struct{}) error {
+	samples, err := drain(src, stop, []float32{})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.Create(s.Path)
+	if err != nil {
+		return err
+	}
+	if err := WriteWAV(f, samples); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// drain reads src to the end, appending to out when it is non-nil.
+func drain(src Source, stop <-chan struct{}, out []float32) ([]float32, error) {
+	buf := make([]float32, 4096)
+	for {
+		select {
+		case <-stop:
+			return out, nil
+		default:
+		}
+		n, err := src.Read(buf)
+		if out != nil {
+			out = append(out, buf[:n]...)
+		}
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return out, err
+		}
+	}
+}
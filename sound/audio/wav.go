@@ -0,0 +1,137 @@
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+const (
+	wavPCM   = 1
+	wavFloat = 3
+)
+
+// the largest fmt chunk we accept; WAVE_FORMAT_EXTENSIBLE needs 40
+const maxFmtSize = 1 << 10
+
+var errBadWAV = errors.New("audio: unsupported wav file")
+
+func decodeWAV(r io.Reader) (*Buffer, error) {
+	var riff [12]byte
+	if _, err := io.ReadFull(r, riff[:]); err != nil {
+		return nil, err
+	}
+	if string(riff[:4]) != "RIFF" || string(riff[8:]) != "WAVE" {
+		return nil, errBadWAV
+	}
+
+	var format, channels, bits uint16
+	var rate uint32
+	for {
+		var hdr [8]byte
+		if _, err := io.ReadFull(r, hdr[:]); err != nil {
+			return nil, errBadWAV
+		}
+		id := string(hdr[:4])
+		// sizes come from the file, so never allocate by them up front
+		size := int64(binary.LittleEndian.Uint32(hdr[4:]))
+
+		switch id {
+		case "fmt ":
+			if size < 16 || size > maxFmtSize {
+				return nil, errBadWAV
+			}
+			chunk := make([]byte, size+size%2)
+			if _, err := io.ReadFull(r, chunk); err != nil {
+				return nil, errBadWAV
+			}
+			format = binary.LittleEndian.Uint16(chunk[0:])
+			channels = binary.LittleEndian.Uint16(chunk[2:])
+			rate = binary.LittleEndian.Uint32(chunk[4:])
+			bits = binary.LittleEndian.Uint16(chunk[14:])
+			// WAVE_FORMAT_EXTENSIBLE keeps the real format in the sub-GUID
+			if format == 0xFFFE && size >= 26 {
+				format = binary.LittleEndian.Uint16(chunk[24:])
+			}
+		case "data":
+			if channels == 0 {
+				return nil, errBadWAV
+			}
+			// a truncated file plays what is there
+			data, err := io.ReadAll(io.LimitReader(r, size))
+			if err != nil {
+				return nil, err
+			}
+			samples, err := wavSamples(data, format, bits)
+			if err != nil {
+				return nil, err
+			}
+			return toStereo(samples, int(channels), int(rate)), nil
+		default:
+			if _, err := io.CopyN(io.Discard, r, size+size%2); err != nil {
+				return nil, errBadWAV
+			}
+		}
+	}
+}
+
+func wavSamples(data []byte, format, bits uint16) ([]float32, error) {
+	width := int(bits) / 8
+	if width == 0 {
+		return nil, errBadWAV
+	}
+	out := make([]float32, len(data)/width)
+	for i := range out {
+		b := data[i*width:]
+		switch {
+		case format == wavPCM && bits == 8:
+			out[i] = (float32(b[0]) - 128) / 128
+		case format == wavPCM && bits == 16:
+			out[i] = float32(int16(binary.LittleEndian.Uint16(b))) / 32768
+		case format == wavPCM && bits == 24:
+			v := int32(b[0]) | int32(b[1])<<8 | int32(int8(b[2]))<<16
+			out[i] = float32(v) / (1 << 23)
+		case format == wavPCM && bits == 32:
+			out[i] = float32(int32(binary.LittleEndian.Uint32(b))) / (1 << 31)
+		case format == wavFloat && bits == 32:
+			out[i] = math.Float32frombits(binary.LittleEndian.Uint32(b))
+		default:
+			return nil, errBadWAV
+		}
+	}
+	return out, nil
+}
+
+// WriteWAV encodes stereo samples at SampleRate as 16-bit PCM.
+func WriteWAV(w io.Writer, samples []float32) error {
+	dataSize := uint32(len(samples) * 2)
+	hdr := make([]byte, 44)
+	copy(hdr[0:], "RIFF")
+	binary.LittleEndian.PutUint32(hdr[4:], 36+dataSize)
+	copy(hdr[8:], "WAVEfmt ")
+	binary.LittleEndian.PutUint32(hdr[16:], 16)
+	binary.LittleEndian.PutUint16(hdr[20:], wavPCM)
+	binary.LittleEndian.PutUint16(hdr[22:], Channels)
+	binary.LittleEndian.PutUint32(hdr[24:], SampleRate)
+	binary.LittleEndian.PutUint32(hdr[28:], SampleRate*Channels*2)
+	binary.LittleEndian.PutUint16(hdr[32:], Channels*2)
+	binary.LittleEndian.PutUint16(hdr[34:], 16)
+	copy(hdr[36:], "data")
+	binary.LittleEndian.PutUint32(hdr[40:], dataSize)
+	if _, err := w.Write(hdr); err != nil {
+		return err
+	}
+
+	data := make([]byte, dataSize)
+	putInt16(data, samples)
+	_, err := w.Write(data)
+	return err
+}
+
+// putInt16 writes samples into dst as little endian 16-bit PCM.
+func putInt16(dst []byte, samples []float32) {
+	for i, s := range samples {
+		binary.LittleEndian.PutUint16(dst[2*i:], uint16(int16(clamp(s)*32767)))
+	}
+}
@@ -0,0 +1,69 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"runtime"
+	"testing"
+)
+
+// wavHeader is a 16-bit stereo fmt chunk followed by a data chunk
+// header claiming dataSize bytes.
+func wavHeader(fmtSize, dataSize uint32) []byte {
+	var b bytes.Buffer
+	b.WriteString("RIFF")
+	_ = binary.Write(&b, binary.LittleEndian, uint32(0xFFFFFFFF))
+	b.WriteString("WAVEfmt ")
+	_ = binary.Write(&b, binary.LittleEndian, fmtSize)
+	fmtChunk := make([]byte, 16)
+	binary.LittleEndian.PutUint16(fmtChunk[0:], wavPCM)
+	binary.LittleEndian.PutUint16(fmtChunk[2:], Channels)
+	binary.LittleEndian.PutUint32(fmtChunk[4:], SampleRate)
+	binary.LittleEndian.PutUint16(fmtChunk[14:], 16)
+	b.Write(fmtChunk)
+	b.WriteString("data")
+	_ = binary.Write(&b, binary.LittleEndian, dataSize)
+	return b.Bytes()
+}
+
+func TestWAVRoundTrip(t *testing.T) {
+	samples := []float32{0, 0, 0.5, 0.5, -0.5, -0.5, 1, 1}
+	var b bytes.Buffer
+	if err := WriteWAV(&b, samples); err != nil {
+		t.Fatal(err)
+	}
+	buf, err := Decode(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.Frames() != len(samples)/Channels {
+		t.Fatalf("%d frames, want %d", buf.Frames(), len(samples)/Channels)
+	}
+}
+
+func TestWAVHugeDataChunk(t *testing.T) {
+	// the header claims 4 GB but only four frames follow
+	input := append(wavHeader(16, 0xFFFFFFFF), make([]byte, 16)...)
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	buf, err := Decode(bytes.NewReader(input))
+	runtime.ReadMemStats(&after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.Frames() != 4 {
+		t.Fatalf("%d frames, want 4", buf.Frames())
+	}
+	if n := after.TotalAlloc - before.TotalAlloc; n > 1<<20 {
+		t.Fatalf("decoding allocated %d bytes", n)
+	}
+}
+
+func TestWAVBadFmtSize(t *testing.T) {
+	for _, size := range []uint32{0, 8, 0xFFFFFFFF} {
+		input := append(wavHeader(size, 0), make([]byte, 64)...)
+		if _, err := Decode(bytes.NewReader(input)); err == nil {
+			t.Errorf("fmt size %#x accepted", size)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package audio
+
+import (
+	"fmt"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+var (
+	winmm                = syscall.NewLazyDLL("winmm.dll")
+	procWaveOutOpen      = winmm.NewProc("waveOutOpen")
+	procWaveOutClose     = winmm.NewProc("waveOutClose")
+	procWaveOutPrepare   = winmm.NewProc("waveOutPrepareHeader")
+	procWaveOutUnprepare = winmm.NewProc("waveOutUnprepareHeader")
+	procWaveOutWrite     = winmm.NewProc("waveOutWrite")
+	procWaveOutReset     = winmm.NewProc("waveOutReset")
+)
+
+const (
+	waveMapper    = 0xFFFFFFFF
+	callbackNull  = 0
+	waveFormatPCM = 1
+	whdrDone      = 0x00000001
+	mmsysErrNoErr = 0
+
+	waveOutPoll             = 10 * time.Millisecond
+	waveOutBuffers          = 4
+	waveOutFramesPerBuffer  = SampleRate / 10
+	waveOutBytesPerFrame    = Channels * 2
+	waveOutSamplesPerBuffer = waveOutFramesPerBuffer * Channels
+)
+
+type waveFormatEx struct {
+	FormatTag      uint16
+	Channels       uint16
+	SamplesPerSec  uint32
+	AvgBytesPerSec uint32
+	BlockAlign     uint16
+	BitsPerSample  uint16
+	Size           uint16
+}
+
+type waveHdr struct {
+	Data          uintptr
+	BufferLength  uint32
+	BytesRecorded uint32
+	User          uintptr
+	Flags         uint32
+	Loops         uint32
+	Next          uintptr
+	Reserved      uintptr
+}
+
+const waveHdrSize = unsafe.Sizeof(waveHdr{})
+
+// WaveOut plays through the WinMM waveOut API, which unlike PlaySoundW
+// takes raw PCM, so anything the decoders understand can be played.
+type WaveOut struct{}
+
+func NewWaveOut() WaveOut {
+	return WaveOut{}
+}
+
+func (WaveOut) Play(src Source, stop <-chan struct{}) error {
+	format := waveFormatEx{
+		FormatTag:      waveFormatPCM,
+		Channels:       Channels,
+		SamplesPerSec:  SampleRate,
+		AvgBytesPerSec: SampleRate * uint32(waveOutBytesPerFrame),
+		BlockAlign:     uint16(waveOutBytesPerFrame),
+		BitsPerSample:  16,
+	}
+	var h uintptr
+	if r, _, _ := procWaveOutOpen.Call(
+		uintptr(unsafe.Pointer(&h)),
+		waveMapper,
+		uintptr(unsafe.Pointer(&format)),
+		0, 0, callbackNull,
+	); r != mmsysErrNoErr {
+		return fmt.Errorf("audio: waveOutOpen failed: %d", r)
+	}
+	defer procWaveOutClose.Call(h)
+
+	// a small ring of buffers keeps the device fed while the next one fills
+	hdrs := make([]waveHdr, waveOutBuffers)
+	data := make([][]byte, waveOutBuffers)
+	samples := make([]float32, waveOutSamplesPerBuffer)
+	queued := 0
+	ended := false
+
+	fill := func(i int) bool {
+		n := 0
+		for n < len(samples) && !ended {
+			m, err := src.Read(samples[n:])
+			n += m
+			if err != nil {
+				ended = true
+			}
+		}
+		if n == 0 {
+			return false
+		}
+		putInt16(data[i], samples[:n])
+		hdrs[i] = waveHdr{
+			Data:         uintptr(unsafe.Pointer(&data[i][0])),
+			BufferLength: uint32(n * 2),
+		}
+		procWaveOutPrepare.Call(h, uintptr(unsafe.Pointer(&hdrs[i])), waveHdrSize)
+		procWaveOutWrite.Call(h, uintptr(unsafe.Pointer(&hdrs[i])), waveHdrSize)
+		queued++
+		return true
+	}
+
+	for i := range data {
+		data[i] = make([]byte, waveOutSamplesPerBuffer*2)
+	}
+	for i := range hdrs {
+		if !fill(i) {
+			break
+		}
+	}
+
+	for i := 0; queued > 0; i = (i + 1) % waveOutBuffers {
+		if hdrs[i].Data == 0 {
+			continue
+		}
+		for hdrs[i].Flags&whdrDone == 0 {
+			select {
+			case <-stop:
+				procWaveOutReset.Call(h)
+				for j := range hdrs {
+					if hdrs[j].Data != 0 {
+						procWaveOutUnprepare.Call(h, uintptr(unsafe.Pointer(&hdrs[j])), waveHdrSize)
+					}
+				}
+				return nil
+			case <-time.After(waveOutPoll):
+			}
+		}
+		procWaveOutUnprepare.Call(h, uintptr(unsafe.Pointer(&hdrs[i])), waveHdrSize)
+		hdrs[i] = waveHdr{}
+		queued--
+		fill(i)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package sound
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/catalinfl/nuisance/appdir"
+	"github.com/catalinfl/nuisance/sound/audio"
+)
+
+// note is one sine tone of a built-in sound, with a quick attack and an
+// exponential tail.
+type note struct {
+	freq  float64
+	start float64 // seconds
+	dur   float64
+	level float64
+}
+
+var builtinNotes = map[Event][]note{
+	WorkStart:  {{660, 0, 0.25, 0.5}, {880, 0.18, 0.4, 0.5}},
+	BreakStart: {{880, 0, 0.25, 0.5}, {660, 0.18, 0.4, 0.5}},
+	Complete:   {{523, 0, 0.3, 0.4}, {659, 0.12, 0.3, 0.4}, {784, 0.24, 0.6, 0.45}},
+	Button:     {{1500, 0, 0.03, 0.25}},
+	WorkAlarm:  {{988, 0, 0.15, 0.6}, {988, 0.22, 0.15, 0.6}, {988, 0.44, 0.3, 0.6}},
+	BreakAlarm: {{740, 0, 0.15, 0.6}, {740, 0.22, 0.15, 0.6}, {740, 0.44, 0.3, 0.6}},
+	Warning:    {{1047, 0, 0.5, 0.2}},
+}
+
+// Synthesize renders the built-in sound for e as stereo samples at
+// audio.SampleRate.
+func Synthesize(e Event) []float32 {
+	notes, ok := builtinNotes[e]
+	if !ok {
+		notes = builtinNotes[Complete]
+	}
+	end := 0.0
+	for _, n := range notes {
+		end = max(end, n.start+n.dur)
+	}
+
+	frames := int(end * audio.SampleRate)
+	out := make([]float32, frames*audio.Channels)
+	for _, n := range notes {
+		first := int(n.start * audio.SampleRate)
+		length := int(n.dur * audio.SampleRate)
+		for i := 0; i < length && first+i < frames; i++ {
+			t := float64(i) / audio.SampleRate
+			env := math.Exp(-5 * t / n.dur)
+			if attack := 0.005; t < attack {
+				env *= t / attack
+			}
+			v := float32(n.level * env * math.Sin(2*math.Pi*n.freq*t))
+			out[2*(first+i)] += v
+			out[2*(first+i)+1] += v
+		}
+	}
+	return out
+}
+
+var builtinFiles sync.Map // Event -> path
+
+// builtinPath writes the synthesized sound for e to a WAV file in the
+// user's cache directory once and returns it, so every player, including
+// the WAV-only ones, can use it. The file is written under a temporary
+// name and renamed into place, so a half-written file is never played.
+func builtinPath(e Event) string {
+	if p, ok := builtinFiles.Load(e); ok {
+		return p.(string)
+	}
+	cache, err := appdir.CacheDir()
+	if err != nil {
+		return ""
+	}
+	dir := filepath.Join(cache, "sounds")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return ""
+	}
+	f, err := os.CreateTemp(dir, string(e)+"-*.wav")
+	if err != nil {
+		return ""
+	}
+	err = audio.WriteWAV(f, Synthesize(e))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	path := filepath.Join(dir, string(e)+".wav")
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return ""
+	}
+	builtinFiles.Store(e, path)
+	return path
+}
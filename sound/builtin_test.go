@@ -0,0 +1,42 @@
+package sound
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/catalinfl/nuisance/sound/audio"
+)
+
+func TestBuiltinPathInUserCache(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("XDG_CACHE_HOME only moves the cache on Linux")
+	}
+	cache := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cache)
+	builtinFiles.Delete(Warning)
+
+	path := builtinPath(Warning)
+	if !strings.HasPrefix(path, cache) {
+		t.Fatalf("builtinPath = %q, want a file under %q", path, cache)
+	}
+	info, err := os.Stat(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0700 {
+		t.Errorf("sounds directory mode = %v, want 0700", perm)
+	}
+	buf, err := audio.DecodeFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.Frames() == 0 {
+		t.Error("built-in sound is empty")
+	}
+	if again := builtinPath(Warning); again != path {
+		t.Errorf("second call = %q, want %q", again, path)
+	}
+}
@@ -0,0 +1,50 @@
+package sound
+
+import "sync"
+
+// RecordingPlayer is a fake Player that remembers what it was asked to
+// play instead of making noise. It is safe for concurrent use.
+type RecordingPlayer struct {
+	mu     sync.Mutex
+	played []string
+	levels []float64
+	stops  int
+}
+
+func (p *RecordingPlayer) Play(path string) error {
+	return p.PlayAt(path, 1)
+}
+
+func (p *RecordingPlayer) PlayAt(path string, level float64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.played = append(p.played, path)
+	p.levels = append(p.levels, level)
+	return nil
+}
+
+func (p *RecordingPlayer) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stops++
+}
+
+// Played returns the paths played so far, oldest first.
+func (p *RecordingPlayer) Played() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.played...)
+}
+
+// Levels returns the level of every play, matching Played.
+func (p *RecordingPlayer) Levels() []float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]float64(nil), p.levels...)
+}
+
+func (p *RecordingPlayer) Stops() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stops
+}
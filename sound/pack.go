@@ -0,0 +1,95 @@
+package sound
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync/atomic"
+)
+
+// Pack is a folder under sounds/packs/ with a pack.json manifest:
+//
+//	{
+//	  "name": "Soft bells",
+//	  "volume": 0.8,
+//	  "files": {"work_start": "start.ogg", "work_alarm": "bell.mp3"}
+//	}
+//
+// Events the pack leaves out fall back to the normal sounds.
+type Pack struct {
+	ID     string           `json:"-"` // folder name
+	Dir    string           `json:"-"`
+	Name   string           `json:"name"`
+	Volume float64          `json:"volume"`
+	Files  map[Event]string `json:"files"`
+}
+
+// Path returns the pack's file for e, if it has one.
+func (p *Pack) Path(e Event) (string, bool) {
+	file, ok := p.Files[e]
+	if !ok || file == "" {
+		return "", false
+	}
+	if filepath.IsAbs(file) {
+		return file, true
+	}
+	return filepath.Join(p.Dir, file), true
+}
+
+func PacksDir() string {
+	return soundsFile("packs")
+}
+
+func loadPack(dir string) (*Pack, error) {
+	input, err := os.ReadFile(filepath.Join(dir, "pack.json"))
+	if err != nil {
+		return nil, err
+	}
+	p := &Pack{}
+	if err := json.Unmarshal(input, p); err != nil {
+		return nil, err
+	}
+	p.ID, p.Dir = filepath.Base(dir), dir
+	if p.Name == "" {
+		p.Name = p.ID
+	}
+	return p, nil
+}
+
+// LoadPacks returns every pack with a readable manifest, sorted by name.
+func LoadPacks() []*Pack {
+	entries, err := os.ReadDir(PacksDir())
+	if err != nil {
+		return nil
+	}
+	var packs []*Pack
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if p, err := loadPack(filepath.Join(PacksDir(), entry.Name())); err == nil {
+			packs = append(packs, p)
+		}
+	}
+	sort.Slice(packs, func(i, j int) bool { return packs[i].Name < packs[j].Name })
+	return packs
+}
+
+var activePack atomic.Pointer[Pack]
+
+// usePack loads the pack with the given folder name; "" or a broken
+// pack means none.
+func usePack(id string) *Pack {
+	if id == "" {
+		activePack.Store(nil)
+		return nil
+	}
+	p, err := loadPack(filepath.Join(PacksDir(), id))
+	if err != nil {
+		activePack.Store(nil)
+		return nil
+	}
+	activePack.Store(p)
+	return p
+}
@@ -0,0 +1,307 @@
+package sound
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Event is something the app can make a sound for.
+type Event string
+
+const (
+	WorkStart  Event = "work_start"
+	BreakStart Event = "break_start"
+	Complete   Event = "complete"
+	Button     Event = "button"
+	WorkAlarm  Event = "work_alarm"
+	BreakAlarm Event = "break_alarm"
+	Warning    Event = "warning"
+)
+
+// the files nuisance has always looked for in the sounds folder
+var defaultFiles = map[Event]string{
+	WorkStart:  "work_alarm.mp3",
+	BreakStart: "break_alarm.mp3",
+	Complete:   "complete.mp3",
+	Button:     "button.mp3",
+	WorkAlarm:  "work_alarm.mp3",
+	BreakAlarm: "break_alarm.mp3",
+	Warning:    "warning.mp3",
+}
+
+// Events lists every event in the order Settings shows them.
+var Events = []Event{WorkStart, BreakStart, Complete, Button, WorkAlarm, BreakAlarm, Warning}
+
+func (e Event) Label() string {
+	switch e {
+	case WorkStart:
+		return "Work start"
+	case BreakStart:
+		return "Break start"
+	case Complete:
+		return "Complete"
+	case Button:
+		return "Button clicks"
+	case WorkAlarm:
+		return "Work over alarm"
+	case BreakAlarm:
+		return "Break over alarm"
+	case Warning:
+		return "Time warning"
+	}
+	return string(e)
+}
+
+// EventSettings says whether an event plays and which file it uses. A
+// relative File is looked up in the sounds folder next to the binary;
+// an empty one means the active pack's sound or the default.
+type EventSettings struct {
+	Enabled bool   `json:"enabled"`
+	File    string `json:"file"`
+}
+
+type settingsData struct {
+	Volume  float64                 `json:"volume"`
+	Events  map[Event]EventSettings `json:"events"`
+	Ambient Noise                   `json:"ambient,omitempty"`
+	Tick    bool                    `json:"tick,omitempty"`
+	// minutes an ignored alarm rings before giving up, 0 for never
+	AlarmSilence int `json:"alarm_silence,omitempty"`
+	// folder name of the sound pack under sounds/packs, "" for none
+	Pack string `json:"pack,omitempty"`
+}
+
+func defaultSettings() settingsData {
+	return settingsData{
+		Volume: 1,
+		Events: map[Event]EventSettings{
+			WorkStart:  {Enabled: true},
+			BreakStart: {Enabled: true},
+			Complete:   {Enabled: true},
+			Button:     {Enabled: true},
+			WorkAlarm:  {Enabled: true},
+			BreakAlarm: {Enabled: true},
+			Warning:    {Enabled: true},
+		},
+	}
+}
+
+// Settings holds the volume and per-event choices, saved as JSON after
+// every change.
+type Settings struct {
+	mu   sync.Mutex
+	path string
+	data settingsData
+}
+
+// LoadSettings reads path, filling in defaults for anything missing.
+func LoadSettings(path string) (*Settings, error) {
+	s := &Settings{path: path, data: defaultSettings()}
+
+	input, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	var saved settingsData
+	if err := json.Unmarshal(input, &saved); err != nil {
+		return s, err
+	}
+	s.data.Volume = clampVolume(saved.Volume)
+	s.data.Ambient, s.data.Tick = saved.Ambient, saved.Tick
+	s.data.AlarmSilence = max(saved.AlarmSilence, 0)
+	s.data.Pack = saved.Pack
+	for e, es := range saved.Events {
+		s.data.Events[e] = es
+	}
+	return s, nil
+}
+
+func (s *Settings) save() error {
+	if s.path == "" {
+		return nil
+	}
+	output, err := json.MarshalIndent(s.data, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, output, 0644)
+}
+
+func (s *Settings) Volume() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data.Volume
+}
+
+// SetVolume stores v (0 to 1) and applies it to the player right away.
+func (s *Settings) SetVolume(v float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Volume = clampVolume(v)
+	if current() == s {
+		setPlayerVolume(s.data.Volume)
+	}
+	return s.save()
+}
+
+func (s *Settings) Event(e Event) EventSettings {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data.Events[e]
+}
+
+func (s *Settings) SetEnabled(e Event, enabled bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	es := s.data.Events[e]
+	es.Enabled = enabled
+	s.data.Events[e] = es
+	return s.save()
+}
+
+func (s *Settings) SetFile(e Event, file string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	es := s.data.Events[e]
+	es.File = file
+	s.data.Events[e] = es
+	return s.save()
+}
+
+// Ambient returns the background noise and whether the clock ticks
+// during work.
+func (s *Settings) Ambient() (Noise, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data.Ambient, s.data.Tick
+}
+
+func (s *Settings) SetAmbient(n Noise) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Ambient = n
+	return s.save()
+}
+
+func (s *Settings) SetTick(tick bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Tick = tick
+	return s.save()
+}
+
+// AlarmSilence is how long an unanswered alarm rings, 0 meaning forever.
+func (s *Settings) AlarmSilence() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return time.Duration(s.data.AlarmSilence) * time.Minute
+}
+
+func (s *Settings) SetAlarmSilence(minutes int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.AlarmSilence = max(minutes, 0)
+	return s.save()
+}
+
+func (s *Settings) Pack() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data.Pack
+}
+
+// SetPack switches to the pack in sounds/packs/id, "" for none, and
+// takes over its default volume if it has one.
+func (s *Settings) SetPack(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Pack = id
+	if current() == s {
+		if p := usePack(id); p != nil && p.Volume > 0 {
+			s.data.Volume = clampVolume(p.Volume)
+			setPlayerVolume(s.data.Volume)
+		}
+	}
+	return s.save()
+}
+
+func clampVolume(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
+
+// until Use is called sounds play with the defaults and nothing is saved
+var active atomic.Pointer[Settings]
+
+func init() {
+	active.Store(&Settings{data: defaultSettings()})
+}
+
+func current() *Settings {
+	return active.Load()
+}
+
+// Use makes s the settings every Play call consults.
+func Use(s *Settings) {
+	active.Store(s)
+	usePack(s.Pack())
+	setPlayerVolume(s.Volume())
+}
+
+// EventPath returns the file for e, or false if the event is switched off.
+func EventPath(e Event) (string, bool) {
+	if !current().Event(e).Enabled {
+		return "", false
+	}
+	return GetSoundPath(string(e)), true
+}
+
+// resolveEvent picks the first of these that exists: the file chosen
+// in Settings, the active pack's file, the classic file in the sounds
+// folder, and finally the built-in synthesized sound.
+func resolveEvent(e Event) string {
+	var candidates []string
+	if file := current().Event(e).File; file != "" {
+		if !filepath.IsAbs(file) {
+			file = soundsFile(file)
+		}
+		candidates = append(candidates, file)
+	}
+	if p := activePack.Load(); p != nil {
+		if file, ok := p.Path(e); ok {
+			candidates = append(candidates, file)
+		}
+	}
+	candidates = append(candidates, soundsFile(defaultFiles[e]))
+
+	for _, file := range candidates {
+		if _, err := os.Stat(file); err == nil {
+			return file
+		}
+	}
+	return builtinPath(e)
+}
+
+func isEvent(e Event) bool {
+	_, ok := defaultFiles[e]
+	return ok
+}
+
+// Play plays the sound configured for e, if it is enabled.
+func Play(e Event) {
+	if path, ok := EventPath(e); ok {
+		PlaySound(path)
+	}
+}
@@ -3,92 +3,124 @@ package sound
 import (
 	"os"
 	"path/filepath"
-	"syscall"
-	"time"
-	"unsafe"
 )
 
-var (
-	winmm         = syscall.NewLazyDLL("winmm.dll")
-	procPlaySound = winmm.NewProc("PlaySoundW")
-)
+// Player plays sound files. Each platform provides its own in
+// sound_<os>.go; NoopPlayer is the silent fallback.
+type Player interface {
+	Play(path string) error
+	Stop()
+}
 
-const (
-	SND_FILENAME = 0x00020000
-	SND_ASYNC    = 0x0001
-	SND_LOOP     = 0x0008
-)
+type NoopPlayer struct{}
+
+func (NoopPlayer) Play(path string) error { return nil }
+func (NoopPlayer) Stop()                  {}
 
-type AlarmPlayer struct {
-	stopChan chan struct{}
-	playing  bool
+// VolumeSetter is implemented by players that can scale their output.
+// v runs from 0 (silent) to 1 (as recorded).
+type VolumeSetter interface {
+	SetVolume(v float64)
 }
 
-func NewAlarmPlayer() *AlarmPlayer {
-	return &AlarmPlayer{
-		stopChan: make(chan struct{}),
-		playing:  false,
+// LevelPlayer is implemented by players that can play a single sound
+// quieter than the volume setting, e.g. for the opening of an alarm.
+type LevelPlayer interface {
+	PlayAt(path string, level float64) error
+}
+
+func setPlayerVolume(v float64) {
+	if vs, ok := player.(VolumeSetter); ok {
+		vs.SetVolume(v)
 	}
+	ambient.setVolume(v)
+}
+
+// fallbackPlayer tries the in-process decoder first and hands anything
+// it cannot play to the older system player.
+type fallbackPlayer struct {
+	primary, secondary Player
 }
 
-func (ap *AlarmPlayer) Stop() {
-	if ap.playing {
-		ap.playing = false
-		close(ap.stopChan)
-		ap.stopChan = make(chan struct{})
-		StopSound()
+func (p fallbackPlayer) Play(path string) error {
+	if err := p.primary.Play(path); err == nil {
+		return nil
 	}
+	return p.secondary.Play(path)
 }
 
-func (ap *AlarmPlayer) PlayRepeating(soundPath string) {
-	if ap.playing {
-		return
+func (p fallbackPlayer) PlayAt(path string, level float64) error {
+	if lp, ok := p.primary.(LevelPlayer); ok {
+		if err := lp.PlayAt(path, level); err == nil {
+			return nil
+		}
+	} else if err := p.primary.Play(path); err == nil {
+		return nil
 	}
-	ap.playing = true
-	go func() {
-		ticker := time.NewTicker(2 * time.Second)
-		defer ticker.Stop()
-
-		PlaySound(soundPath)
-
-		for {
-			select {
-			case <-ticker.C:
-				PlaySound(soundPath)
-			case <-ap.stopChan:
-				return
-			}
+	return p.secondary.Play(path)
+}
+
+func (p fallbackPlayer) Stop() {
+	p.primary.Stop()
+	p.secondary.Stop()
+}
+
+func (p fallbackPlayer) SetVolume(v float64) {
+	for _, q := range []Player{p.primary, p.secondary} {
+		if vs, ok := q.(VolumeSetter); ok {
+			vs.SetVolume(v)
 		}
-	}()
+	}
+}
+
+var player Player = newPlayer()
+
+// SetPlayer replaces the platform player, e.g. with NoopPlayer in tests.
+func SetPlayer(p Player) {
+	player = p
+	setPlayerVolume(current().Volume())
 }
 
 func PlaySound(soundPath string) error {
+	return PlaySoundAt(soundPath, 1)
+}
+
+// PlaySoundAt plays soundPath scaled by level (0 to 1) where the player
+// supports it, and at the normal volume otherwise.
+func PlaySoundAt(soundPath string, level float64) error {
 	if soundPath == "" {
 		return nil
 	}
 
 	if _, err := os.Stat(soundPath); os.IsNotExist(err) {
-		soundPath = "C:\\Windows\\Media\\Windows Notify System Generic.wav"
+		soundPath = builtinPath(Complete)
 	}
+	return playOn(player, soundPath, level)
+}
 
-	soundPtr, err := syscall.UTF16PtrFromString(soundPath)
-	if err != nil {
-		return err
+func playOn(p Player, soundPath string, level float64) error {
+	if lp, ok := p.(LevelPlayer); ok {
+		return lp.PlayAt(soundPath, level)
 	}
-
-	procPlaySound.Call(
-		uintptr(unsafe.Pointer(soundPtr)),
-		0,
-		SND_ASYNC|SND_FILENAME,
-	)
-	return nil
+	return p.Play(soundPath)
 }
 
 func StopSound() {
-	procPlaySound.Call(0, 0, 0)
+	player.Stop()
+}
+
+// GetSoundPath resolves name to a file. Event names such as
+// "work_alarm" go through the settings, the active pack, the sounds
+// folder and the built-in sounds; anything else is taken as a file name
+// in the sounds folder next to the binary.
+func GetSoundPath(name string) string {
+	if e := Event(name); isEvent(e) {
+		return resolveEvent(e)
+	}
+	return soundsFile(name)
 }
 
-func GetSoundPath(filename string) string {
+func soundsFile(filename string) string {
 	exePath, err := os.Executable()
 	if err != nil {
 		return filename
@@ -99,17 +131,17 @@ func GetSoundPath(filename string) string {
 }
 
 func PlayWorkStart() {
-	PlaySound(GetSoundPath("work_alarm.mp3"))
+	Play(WorkStart)
 }
 
 func PlayBreakStart() {
-	PlaySound(GetSoundPath("break_alarm.mp3"))
+	Play(BreakStart)
 }
 
 func PlayComplete() {
-	PlaySound(GetSoundPath("complete.mp3"))
+	Play(Complete)
 }
 
 func PlayButton() {
-	PlaySound(GetSoundPath("button.mp3"))
+	Play(Button)
 }
@@ -0,0 +1,103 @@
+package sound
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sync"
+
+	"github.com/catalinfl/nuisance/sound/audio"
+)
+
+// commandPlayer hands files to the first command line player found:
+// PipeWire, PulseAudio, then plain ALSA.
+type commandPlayer struct {
+	mu     sync.Mutex
+	bin    string
+	proc   *exec.Cmd
+	volume float64
+}
+
+// one client serves both the effects player and the ambient stream
+var pulse = audio.NewPulse()
+
+func newPlayer() Player {
+	return fallbackPlayer{
+		primary:   &pulsePlayer{Player: audio.NewPlayer(pulse), pulse: pulse},
+		secondary: newCommandPlayer(),
+	}
+}
+
+// pulsePlayer decodes in process and streams to PulseAudio. Play only
+// reports decode errors, so check the server is reachable up front to
+// let the command player take over on bare ALSA systems.
+type pulsePlayer struct {
+	*audio.Player
+	pulse *audio.Pulse
+}
+
+func (p *pulsePlayer) Play(path string) error {
+	return p.PlayAt(path, 1)
+}
+
+func (p *pulsePlayer) PlayAt(path string, level float64) error {
+	if err := p.pulse.Connect(); err != nil {
+		return err
+	}
+	return p.Player.PlayAt(path, level)
+}
+
+// the command players cannot take a stream, so ambient sound needs pulse
+func newStreamBackend() audio.Backend {
+	return pulse
+}
+
+func newCommandPlayer() Player {
+	for _, bin := range []string{"pw-play", "paplay", "aplay"} {
+		if path, err := exec.LookPath(bin); err == nil {
+			return &commandPlayer{bin: path, volume: 1}
+		}
+	}
+	return NoopPlayer{}
+}
+
+func (p *commandPlayer) Play(soundPath string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.stopLocked()
+	var args []string
+	switch filepath.Base(p.bin) {
+	case "pw-play":
+		args = append(args, fmt.Sprintf("--volume=%.2f", p.volume))
+	case "paplay":
+		args = append(args, fmt.Sprintf("--volume=%d", int(p.volume*65536)))
+	}
+	cmd := exec.Command(p.bin, append(args, soundPath)...)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	p.proc = cmd
+	go cmd.Wait()
+	return nil
+}
+
+func (p *commandPlayer) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stopLocked()
+}
+
+// SetVolume takes effect from the next sound; aplay has no volume flag.
+func (p *commandPlayer) SetVolume(v float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.volume = v
+}
+
+func (p *commandPlayer) stopLocked() {
+	if p.proc != nil && p.proc.Process != nil {
+		_ = p.proc.Process.Kill()
+	}
+	p.proc = nil
+}
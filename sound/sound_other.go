@@ -0,0 +1,13 @@
+//go:build !windows && !linux
+
+package sound
+
+import "github.com/catalinfl/nuisance/sound/audio"
+
+func newPlayer() Player {
+	return NoopPlayer{}
+}
+
+func newStreamBackend() audio.Backend {
+	return nil
+}
@@ -0,0 +1,52 @@
+package sound
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/catalinfl/nuisance/sound/audio"
+)
+
+var (
+	winmm         = syscall.NewLazyDLL("winmm.dll")
+	procPlaySound = winmm.NewProc("PlaySoundW")
+)
+
+const (
+	SND_FILENAME = 0x00020000
+	SND_ASYNC    = 0x0001
+	SND_LOOP     = 0x0008
+)
+
+func newStreamBackend() audio.Backend {
+	return audio.NewWaveOut()
+}
+
+// winmmPlayer is the PlaySoundW path; it only understands WAV, so it is
+// kept as the fallback behind the waveOut decoder.
+type winmmPlayer struct{}
+
+func newPlayer() Player {
+	return fallbackPlayer{
+		primary:   audio.NewPlayer(audio.NewWaveOut()),
+		secondary: winmmPlayer{},
+	}
+}
+
+func (winmmPlayer) Play(soundPath string) error {
+	soundPtr, err := syscall.UTF16PtrFromString(soundPath)
+	if err != nil {
+		return err
+	}
+
+	procPlaySound.Call(
+		uintptr(unsafe.Pointer(soundPtr)),
+		0,
+		SND_ASYNC|SND_FILENAME,
+	)
+	return nil
+}
+
+func (winmmPlayer) Stop() {
+	procPlaySound.Call(0, 0, 0)
+}
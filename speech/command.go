@@ -0,0 +1,44 @@
+//go:build !windows
+
+package speech
+
+import (
+	"os/exec"
+	"sync"
+)
+
+// commandSpeaker runs a command line engine, passing the text as the
+// last argument and killing the previous utterance on every Say.
+type commandSpeaker struct {
+	mu   sync.Mutex
+	bin  string
+	args []string
+	proc *exec.Cmd
+}
+
+func (s *commandSpeaker) Say(text string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stopLocked()
+	cmd := exec.Command(s.bin, append(append([]string(nil), s.args...), "--", text)...)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	s.proc = cmd
+	go cmd.Wait()
+	return nil
+}
+
+func (s *commandSpeaker) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopLocked()
+}
+
+func (s *commandSpeaker) stopLocked() {
+	if s.proc != nil && s.proc.Process != nil {
+		_ = s.proc.Process.Kill()
+	}
+	s.proc = nil
+}
@@ -0,0 +1,25 @@
+package speech
+
+import "sync"
+
+// RecordingSpeaker is a fake Speaker that keeps what it was asked to
+// say. It is safe for concurrent use.
+type RecordingSpeaker struct {
+	mu   sync.Mutex
+	said []string
+}
+
+func (s *RecordingSpeaker) Say(text string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.said = append(s.said, text)
+	return nil
+}
+
+func (s *RecordingSpeaker) Stop() {}
+
+func (s *RecordingSpeaker) Said() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.said...)
+}
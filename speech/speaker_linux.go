@@ -0,0 +1,12 @@
+package speech
+
+import "os/exec"
+
+func newSpeaker() Speaker {
+	for _, bin := range []string{"espeak-ng", "espeak"} {
+		if path, err := exec.LookPath(bin); err == nil {
+			return &commandSpeaker{bin: path, args: []string{"-s", "160"}}
+		}
+	}
+	return NoopSpeaker{}
+}
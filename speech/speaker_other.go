@@ -0,0 +1,7 @@
+//go:build !windows && !linux
+
+package speech
+
+func newSpeaker() Speaker {
+	return NoopSpeaker{}
+}
@@ -0,0 +1,59 @@
+package speech
+
+import (
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+const createNoWindow = 0x08000000
+
+// the text comes in on stdin so it never needs quoting
+const sapiScript = `Add-Type -AssemblyName System.Speech; ` +
+	`(New-Object System.Speech.Synthesis.SpeechSynthesizer).Speak([Console]::In.ReadToEnd())`
+
+// sapiSpeaker drives SAPI through PowerShell's System.Speech, which is
+// present on every supported Windows.
+type sapiSpeaker struct {
+	mu   sync.Mutex
+	bin  string
+	proc *exec.Cmd
+}
+
+func newSpeaker() Speaker {
+	path, err := exec.LookPath("powershell.exe")
+	if err != nil {
+		return NoopSpeaker{}
+	}
+	return &sapiSpeaker{bin: path}
+}
+
+func (s *sapiSpeaker) Say(text string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stopLocked()
+	cmd := exec.Command(s.bin, "-NoProfile", "-NonInteractive", "-Command", sapiScript)
+	cmd.Stdin = strings.NewReader(text)
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true, CreationFlags: createNoWindow}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	s.proc = cmd
+	go cmd.Wait()
+	return nil
+}
+
+func (s *sapiSpeaker) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopLocked()
+}
+
+func (s *sapiSpeaker) stopLocked() {
+	if s.proc != nil && s.proc.Process != nil {
+		_ = s.proc.Process.Kill()
+	}
+	s.proc = nil
+}
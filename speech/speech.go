@@ -0,0 +1,174 @@
+// Package speech reads short announcements aloud through the platform's
+// text-to-speech engine.
+package speech
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Speaker turns text into speech. Say must not block until the speech
+// ends; a new Say may cut off the previous one.
+type Speaker interface {
+	Say(text string) error
+	Stop()
+}
+
+type NoopSpeaker struct{}
+
+func (NoopSpeaker) Say(text string) error { return nil }
+func (NoopSpeaker) Stop()                 {}
+
+// DefaultSpeaker returns the platform engine, or NoopSpeaker if none is
+// installed.
+func DefaultSpeaker() Speaker {
+	return newSpeaker()
+}
+
+// Event is something that can be announced.
+type Event string
+
+const (
+	WorkStart Event = "work_start"
+	WorkOver  Event = "work_over"
+	BreakOver Event = "break_over"
+	Warning   Event = "warning"
+)
+
+var Events = []Event{WorkStart, WorkOver, BreakOver, Warning}
+
+func (e Event) Label() string {
+	switch e {
+	case WorkStart:
+		return "Work start"
+	case WorkOver:
+		return "Work over"
+	case BreakOver:
+		return "Break over"
+	case Warning:
+		return "Time warnings"
+	}
+	return string(e)
+}
+
+// Message is what gets said for e. minutes is the length of the coming
+// phase, or the time left for warnings.
+func Message(e Event, minutes int) string {
+	switch e {
+	case WorkStart:
+		if minutes <= 0 {
+			return "Focus time"
+		}
+		return fmt.Sprintf("Focus time, %s", plural(minutes, "minute"))
+	case WorkOver:
+		return fmt.Sprintf("Work session over, take %s", number(minutes))
+	case BreakOver:
+		return "Break over, back to work"
+	case Warning:
+		s := plural(minutes, "minute") + " left"
+		return strings.ToUpper(s[:1]) + s[1:]
+	}
+	return ""
+}
+
+var numbers = []string{"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine", "ten",
+	"eleven", "twelve", "thirteen", "fourteen", "fifteen", "sixteen", "seventeen", "eighteen", "nineteen"}
+
+var tens = []string{"", "", "twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety"}
+
+// number spells n out, which some engines read more naturally than digits.
+func number(n int) string {
+	switch {
+	case n < 0 || n >= 100:
+		return fmt.Sprint(n)
+	case n < len(numbers):
+		return numbers[n]
+	case n%10 == 0:
+		return tens[n/10]
+	}
+	return tens[n/10] + " " + numbers[n%10]
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "one " + unit
+	}
+	return number(n) + " " + unit + "s"
+}
+
+// Settings says which events are announced, saved as JSON after every
+// change. Everything is off until switched on.
+type Settings struct {
+	mu      sync.Mutex
+	path    string
+	enabled map[Event]bool
+}
+
+func LoadSettings(path string) (*Settings, error) {
+	s := &Settings{path: path, enabled: make(map[Event]bool)}
+
+	input, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	err = json.Unmarshal(input, &s.enabled)
+	// a file holding null leaves the map nil
+	if s.enabled == nil {
+		s.enabled = make(map[Event]bool)
+	}
+	return s, err
+}
+
+func (s *Settings) save() error {
+	if s.path == "" {
+		return nil
+	}
+	output, err := json.MarshalIndent(s.enabled, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, output, 0644)
+}
+
+func (s *Settings) Enabled(e Event) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enabled[e]
+}
+
+func (s *Settings) SetEnabled(e Event, enabled bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.enabled[e] = enabled
+	return s.save()
+}
+
+// Announcer speaks the enabled events.
+type Announcer struct {
+	speaker  Speaker
+	settings *Settings
+}
+
+func NewAnnouncer(speaker Speaker, settings *Settings) *Announcer {
+	if settings == nil {
+		settings = &Settings{enabled: make(map[Event]bool)}
+	}
+	return &Announcer{speaker: speaker, settings: settings}
+}
+
+// Announce says the message for e if it is switched on.
+func (a *Announcer) Announce(e Event, minutes int) {
+	if a.settings.Enabled(e) {
+		_ = a.speaker.Say(Message(e, minutes))
+	}
+}
+
+func (a *Announcer) Stop() {
+	a.speaker.Stop()
+}
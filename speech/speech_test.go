@@ -0,0 +1,91 @@
+package speech
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestMessage(t *testing.T) {
+	tests := []struct {
+		e       Event
+		minutes int
+		want    string
+	}{
+		{WorkStart, 25, "Focus time, twenty five minutes"},
+		{WorkStart, 0, "Focus time"},
+		{WorkOver, 5, "Work session over, take five"},
+		{WorkOver, 30, "Work session over, take thirty"},
+		{BreakOver, 0, "Break over, back to work"},
+		{Warning, 1, "One minute left"},
+		{Warning, 2, "Two minutes left"},
+		{Warning, 120, "120 minutes left"},
+	}
+	for _, tt := range tests {
+		if got := Message(tt.e, tt.minutes); got != tt.want {
+			t.Errorf("Message(%s, %d) = %q, want %q", tt.e, tt.minutes, got, tt.want)
+		}
+	}
+}
+
+func TestAnnouncerSpeaksEnabledEvents(t *testing.T) {
+	settings, err := LoadSettings(filepath.Join(t.TempDir(), "speech.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = settings.SetEnabled(WorkOver, true)
+	_ = settings.SetEnabled(Warning, true)
+
+	speaker := &RecordingSpeaker{}
+	a := NewAnnouncer(speaker, settings)
+	a.Announce(WorkStart, 25)
+	a.Announce(WorkOver, 5)
+	a.Announce(BreakOver, 0)
+	a.Announce(Warning, 2)
+
+	want := []string{"Work session over, take five", "Two minutes left"}
+	if got := speaker.Said(); !slices.Equal(got, want) {
+		t.Fatalf("said %q, want %q", got, want)
+	}
+}
+
+func TestAnnouncerWithoutSettings(t *testing.T) {
+	speaker := &RecordingSpeaker{}
+	NewAnnouncer(speaker, nil).Announce(WorkOver, 5)
+	if got := speaker.Said(); len(got) != 0 {
+		t.Fatalf("said %q with nothing enabled", got)
+	}
+}
+
+func TestSettingsRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "speech.json")
+	s, _ := LoadSettings(path)
+	if err := s.SetEnabled(BreakOver, true); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadSettings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.Enabled(BreakOver) || loaded.Enabled(WorkStart) {
+		t.Fatal("settings not restored")
+	}
+}
+
+func TestSettingsNull(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "speech.json")
+	if err := os.WriteFile(path, []byte("null"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := LoadSettings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetEnabled(Warning, true); err != nil {
+		t.Fatal(err)
+	}
+	if !s.Enabled(Warning) {
+		t.Fatal("Warning not enabled")
+	}
+}
@@ -0,0 +1,176 @@
+package tasks
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+type Task struct {
+	// ID stays the same while the task exists, so sessions can point at
+	// it even when titles repeat
+	ID       int    `json:"id"`
+	Title    string `json:"title"`
+	Estimate int    `json:"estimate"`
+	Actual   int    `json:"actual"`
+	Done     bool   `json:"done"`
+}
+
+type list struct {
+	Tasks    []Task `json:"tasks"`
+	Selected int    `json:"selected"`
+	NextID   int    `json:"next_id"`
+}
+
+// List is the task list shown in the Tasks tab, saved as JSON after
+// every change.
+type List struct {
+	mu   sync.Mutex
+	path string
+	data list
+}
+
+// Load reads the list at path. If the file exists but cannot be read,
+// it returns an empty list that is never saved, so the broken file is
+// left for the user instead of being overwritten.
+func Load(path string) (*List, error) {
+	l := &List{path: path, data: list{Selected: -1}}
+
+	input, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return &List{data: list{Selected: -1}}, err
+	}
+	if err := json.Unmarshal(input, &l.data); err != nil {
+		return &List{data: list{Selected: -1}}, err
+	}
+	if l.data.Selected >= len(l.data.Tasks) {
+		l.data.Selected = -1
+	}
+	// lists saved before tasks had IDs
+	for _, t := range l.data.Tasks {
+		l.data.NextID = max(l.data.NextID, t.ID+1)
+	}
+	for i := range l.data.Tasks {
+		if l.data.Tasks[i].ID == 0 {
+			l.data.Tasks[i].ID = l.newID()
+		}
+	}
+	return l, nil
+}
+
+func (l *List) newID() int {
+	l.data.NextID = max(l.data.NextID, 1)
+	id := l.data.NextID
+	l.data.NextID++
+	return id
+}
+
+func (l *List) save() error {
+	if l.path == "" {
+		return nil
+	}
+	output, err := json.MarshalIndent(l.data, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(l.path, output, 0644)
+}
+
+func (l *List) All() []Task {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Task(nil), l.data.Tasks...)
+}
+
+func (l *List) Selected() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.data.Selected
+}
+
+// Current returns the selected task, if any.
+func (l *List) Current() (Task, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.data.Selected < 0 {
+		return Task{}, false
+	}
+	return l.data.Tasks[l.data.Selected], true
+}
+
+func (l *List) Add(title string, estimate int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.data.Tasks = append(l.data.Tasks, Task{ID: l.newID(), Title: title, Estimate: estimate})
+	return l.save()
+}
+
+func (l *List) Remove(i int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if i < 0 || i >= len(l.data.Tasks) {
+		return nil
+	}
+	l.data.Tasks = append(l.data.Tasks[:i], l.data.Tasks[i+1:]...)
+	if l.data.Selected == i {
+		l.data.Selected = -1
+	} else if l.data.Selected > i {
+		l.data.Selected--
+	}
+	return l.save()
+}
+
+// Select makes task i current; selecting it again clears the selection.
+func (l *List) Select(i int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if i < 0 || i >= len(l.data.Tasks) {
+		return nil
+	}
+	if l.data.Selected == i {
+		l.data.Selected = -1
+	} else {
+		l.data.Selected = i
+	}
+	return l.save()
+}
+
+func (l *List) ToggleDone(i int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if i < 0 || i >= len(l.data.Tasks) {
+		return nil
+	}
+	l.data.Tasks[i].Done = !l.data.Tasks[i].Done
+	return l.save()
+}
+
+// CountPomodoro adds a completed pomodoro to the task with the given ID,
+// even if it was marked done while the pomodoro ran. A removed task gets
+// nothing.
+func (l *List) CountPomodoro(id int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i := range l.data.Tasks {
+		if l.data.Tasks[i].ID == id {
+			l.data.Tasks[i].Actual++
+			return l.save()
+		}
+	}
+	return nil
+}
+
+// Find returns the index of the task with the given ID, or -1.
+func (l *List) Find(id int) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, t := range l.data.Tasks {
+		if t.ID == id {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,83 @@
+package tasks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCountPomodoroByID(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	l, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = l.Add("review", 2)
+	_ = l.Add("review", 3)
+	second := l.All()[1].ID
+
+	// the selected task was marked done while the pomodoro ran
+	_ = l.ToggleDone(1)
+	if err := l.CountPomodoro(second); err != nil {
+		t.Fatal(err)
+	}
+	all := l.All()
+	if all[0].Actual != 0 || all[1].Actual != 1 {
+		t.Fatalf("actual = %d, %d, want 0, 1", all[0].Actual, all[1].Actual)
+	}
+
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reloaded.Find(second); got != 1 {
+		t.Fatalf("Find(%d) = %d after reload, want 1", second, got)
+	}
+}
+
+func TestIDsNotReused(t *testing.T) {
+	l, _ := Load(filepath.Join(t.TempDir(), "tasks.json"))
+	_ = l.Add("a", 1)
+	_ = l.Add("b", 1)
+	removed := l.All()[1].ID
+	_ = l.Remove(1)
+	_ = l.Add("c", 1)
+	if l.All()[1].ID == removed {
+		t.Fatal("new task reused a removed task's ID")
+	}
+	if err := l.CountPomodoro(removed); err != nil || l.All()[1].Actual != 0 {
+		t.Fatal("removed task's pomodoro credited to another task")
+	}
+}
+
+func TestLoadAssignsMissingIDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	old := `{"tasks":[{"title":"a"},{"title":"b"}],"selected":1}`
+	if err := os.WriteFile(path, []byte(old), 0644); err != nil {
+		t.Fatal(err)
+	}
+	l, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	all := l.All()
+	if all[0].ID == 0 || all[1].ID == 0 || all[0].ID == all[1].ID {
+		t.Fatalf("IDs = %d, %d", all[0].ID, all[1].ID)
+	}
+}
+
+func TestLoadCorruptKeepsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	l, err := Load(path)
+	if err == nil {
+		t.Fatal("Load accepted a corrupt file")
+	}
+	_ = l.Add("new", 1)
+	data, _ := os.ReadFile(path)
+	if string(data) != "{not json" {
+		t.Fatalf("corrupt file overwritten: %q", data)
+	}
+}
@@ -0,0 +1,76 @@
+package tray
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// IconSize is the width and height of the tray icon in pixels.
+const IconSize = 32
+
+// digits is a 3x5 pixel font, one row per string.
+var digits = [10][5]string{
+	{"###", "#.#", "#.#", "#.#", "###"},
+	{".#.", "##.", ".#.", ".#.", "###"},
+	{"###", "..#", "###", "#..", "###"},
+	{"###", "..#", "###", "..#", "###"},
+	{"#.#", "#.#", "###", "..#", "..#"},
+	{"###", "#..", "###", "..#", "###"},
+	{"###", "#..", "###", "#.#", "###"},
+	{"###", "..#", ".#.", ".#.", ".#."},
+	{"###", "#.#", "###", "#.#", "###"},
+	{"###", "#.#", "###", "..#", "###"},
+}
+
+// Icon draws a disc in c with minutes written on it in white. Negative
+// minutes draw the disc alone; more than 99 is shown as 99.
+func Icon(minutes int, c color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, IconSize, IconSize))
+
+	// the disc, with a one pixel soft edge
+	center := float64(IconSize) / 2
+	for y := range IconSize {
+		for x := range IconSize {
+			dx, dy := float64(x)+0.5-center, float64(y)+0.5-center
+			cover := center - math.Hypot(dx, dy)
+			if cover <= 0 {
+				continue
+			}
+			px := c
+			px.A = uint8(float64(c.A) * min(cover, 1))
+			img.SetNRGBA(x, y, px)
+		}
+	}
+	if minutes < 0 {
+		return img
+	}
+
+	text := []int{min(minutes, 99) % 10}
+	if minutes >= 10 {
+		text = []int{min(minutes, 99) / 10, min(minutes, 99) % 10}
+	}
+	scale := 4
+	if len(text) == 2 {
+		scale = 3
+	}
+	width := len(text)*3*scale + (len(text)-1)*scale
+	left, top := (IconSize-width)/2, (IconSize-5*scale)/2
+	white := color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	for i, d := range text {
+		x0 := left + i*4*scale
+		for row, line := range digits[d] {
+			for col, ch := range line {
+				if ch != '#' {
+					continue
+				}
+				for y := range scale {
+					for x := range scale {
+						img.SetNRGBA(x0+col*scale+x, top+row*scale+y, white)
+					}
+				}
+			}
+		}
+	}
+	return img
+}
@@ -0,0 +1,14 @@
+package tray
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+)
+
+// encodeIcon returns the PNG the StatusNotifierItem host is sent.
+func encodeIcon(img image.Image) []byte {
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, img)
+	return buf.Bytes()
+}
@@ -0,0 +1,28 @@
+package tray
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/png"
+)
+
+// encodeIcon wraps img in a single-image .ico, which Shell_NotifyIcon
+// needs. Icons may hold PNG data since Windows Vista.
+func encodeIcon(img image.Image) []byte {
+	var data bytes.Buffer
+	_ = png.Encode(&data, img)
+
+	var buf bytes.Buffer
+	size := img.Bounds().Size()
+	_ = binary.Write(&buf, binary.LittleEndian, struct {
+		Reserved, Type, Count uint16
+	}{0, 1, 1})
+	_ = binary.Write(&buf, binary.LittleEndian, struct {
+		Width, Height, Colors, Reserved uint8
+		Planes, BitCount                uint16
+		Size, Offset                    uint32
+	}{uint8(size.X), uint8(size.Y), 0, 0, 1, 32, uint32(data.Len()), 6 + 16})
+	buf.Write(data.Bytes())
+	return buf.Bytes()
+}
@@ -0,0 +1,143 @@
+//go:build linux || windows
+
+// Package tray shows the timer in the system tray, StatusNotifierItem on
+// Linux and Shell_NotifyIcon on Windows, with a small control menu.
+package tray
+
+import (
+	"image/color"
+	"runtime"
+	"sync"
+
+	"fyne.io/systray"
+)
+
+// Menu holds what the menu items do. They run on the tray's goroutine.
+type Menu struct {
+	Start func()
+	Pause func()
+	Reset func()
+	// ShowWindow shows or hides the main window.
+	ShowWindow func(show bool)
+	Quit       func()
+}
+
+// Status is what the icon shows.
+type Status struct {
+	Minutes int // written on the icon; negative for none
+	Color   color.NRGBA
+	Tooltip string
+	Running bool // Pause is offered instead of Start
+}
+
+var (
+	mu      sync.Mutex
+	ready   bool
+	status  Status
+	visible = true
+	items   struct{ start, pause, window *systray.MenuItem }
+	// the icon is only redrawn when this changes
+	drawn struct {
+		minutes int
+		color   color.NRGBA
+	}
+)
+
+// Run puts the icon in the tray and returns; menu clicks arrive on m.
+func Run(m Menu) {
+	go func() {
+		// Windows delivers the icon's messages to the thread that made it
+		runtime.LockOSThread()
+		systray.Run(func() { onReady(m) }, nil)
+	}()
+}
+
+// Stop removes the icon.
+func Stop() {
+	systray.Quit()
+}
+
+func onReady(m Menu) {
+	systray.SetTitle("Nuisance")
+
+	mu.Lock()
+	items.start = systray.AddMenuItem("Start", "Start or resume the timer")
+	items.pause = systray.AddMenuItem("Pause", "Pause the timer")
+	reset := systray.AddMenuItem("Reset", "Stop the session")
+	systray.AddSeparator()
+	items.window = systray.AddMenuItem("Hide window", "")
+	systray.AddSeparator()
+	quit := systray.AddMenuItem("Quit", "Quit nuisance")
+	ready = true
+	drawn.minutes = -2 // force the first draw
+	apply()
+	mu.Unlock()
+
+	toggleWindow := func() {
+		mu.Lock()
+		visible = !visible
+		show := visible
+		apply()
+		mu.Unlock()
+		m.ShowWindow(show)
+	}
+	systray.SetOnTapped(toggleWindow)
+
+	for {
+		select {
+		case <-items.start.ClickedCh:
+			m.Start()
+		case <-items.pause.ClickedCh:
+			m.Pause()
+		case <-reset.ClickedCh:
+			m.Reset()
+		case <-items.window.ClickedCh:
+			toggleWindow()
+		case <-quit.ClickedCh:
+			m.Quit()
+			return
+		}
+	}
+}
+
+// SetStatus updates the icon, its tooltip and which of Start and Pause
+// is offered.
+func SetStatus(s Status) {
+	mu.Lock()
+	defer mu.Unlock()
+	status = s
+	apply()
+}
+
+// SetWindowVisible tells the tray the window was shown or hidden some
+// other way, so the menu offers the right one.
+func SetWindowVisible(v bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	visible = v
+	apply()
+}
+
+// apply pushes the state to the tray; mu must be held.
+func apply() {
+	if !ready {
+		return
+	}
+	if status.Minutes != drawn.minutes || status.Color != drawn.color {
+		systray.SetIcon(encodeIcon(Icon(status.Minutes, status.Color)))
+		drawn.minutes, drawn.color = status.Minutes, status.Color
+	}
+	systray.SetTooltip(status.Tooltip)
+	if status.Running {
+		items.start.Disable()
+		items.pause.Enable()
+	} else {
+		items.start.Enable()
+		items.pause.Disable()
+	}
+	if visible {
+		items.window.SetTitle("Hide window")
+	} else {
+		items.window.SetTitle("Show window")
+	}
+}
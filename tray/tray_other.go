@@ -0,0 +1,27 @@
+//go:build !linux && !windows
+
+// Package tray shows the timer in the system tray, StatusNotifierItem on
+// Linux and Shell_NotifyIcon on Windows, with a small control menu.
+package tray
+
+import "image/color"
+
+type Menu struct {
+	Start      func()
+	Pause      func()
+	Reset      func()
+	ShowWindow func(show bool)
+	Quit       func()
+}
+
+type Status struct {
+	Minutes int
+	Color   color.NRGBA
+	Tooltip string
+	Running bool
+}
+
+func Run(m Menu)              {}
+func Stop()                   {}
+func SetStatus(s Status)      {}
+func SetWindowVisible(v bool) {}
@@ -0,0 +1,63 @@
+package ui
+
+import (
+	"image/color"
+
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+// MiniLayout is the whole of the mini timer window: the time and a
+// progress bar. Dragging anywhere moves the window.
+func MiniLayout(gtx layout.Context, th *material.Theme, state *AppState) layout.Dimensions {
+	// light text on a dark background, so it stands out over anything
+	pal := th.Palette
+	pal.Fg = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	pal.Bg = color.NRGBA{R: 24, G: 24, B: 28, A: 255}
+	dark := th.WithPalette(pal)
+	th = &dark
+
+	area := clip.Rect{Max: gtx.Constraints.Max}.Push(gtx.Ops)
+	paint.Fill(gtx.Ops, th.Bg)
+	system.ActionInputOp(system.ActionMove).Add(gtx.Ops)
+	area.Pop()
+
+	return layout.UniformInset(unit.Dp(6)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx,
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				return layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					label := material.H5(th, state.PomodoroTime)
+					if now := gtx.Now; now.Before(state.PulseUntil) {
+						label.Color = pulseColor(th, state.PulseUntil.Sub(now))
+						gtx.Execute(op.InvalidateCmd{})
+					}
+					return label.Layout(gtx)
+				})
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				bar := material.ProgressBar(th, state.Progress)
+				bar.TrackColor = color.NRGBA{R: 255, G: 255, B: 255, A: 40}
+				return bar.Layout(gtx)
+			}),
+		)
+	})
+}
+
+func MiniButton(gtx layout.Context, th *material.Theme, btn *widget.Clickable, open bool) layout.Dimensions {
+	text := "Mini Timer"
+	if open {
+		text = "Close Mini Timer"
+	}
+	return layout.UniformInset(unit.Dp(4)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		b := material.Button(th, btn, text)
+		b.Inset = layout.UniformInset(unit.Dp(4))
+		b.TextSize = unit.Sp(12)
+		return b.Layout(gtx)
+	})
+}
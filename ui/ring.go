@@ -0,0 +1,94 @@
+package ui
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"time"
+
+	"gioui.org/f32"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"github.com/catalinfl/nuisance/pomodoro"
+)
+
+// ModeColor is the color of a timer mode, shared by the progress ring
+// and the tray icon.
+func ModeColor(mode pomodoro.Mode) color.NRGBA {
+	switch mode {
+	case pomodoro.WorkMode:
+		return color.NRGBA{R: 214, G: 69, B: 65, A: 255}
+	case pomodoro.BreakMode:
+		return color.NRGBA{R: 46, G: 160, B: 90, A: 255}
+	case pomodoro.WorkAlarmMode, pomodoro.BreakAlarmMode:
+		return color.NRGBA{R: 240, G: 154, B: 26, A: 255}
+	case pomodoro.PauseMode:
+		return color.NRGBA{R: 86, G: 119, B: 173, A: 255}
+	}
+	return color.NRGBA{R: 128, G: 128, B: 128, A: 255}
+}
+
+// ProgressRing draws how far the phase has got as an arc, clockwise from
+// the top. Between the once-a-second updates the arc moves on at the
+// timer's rate from when the progress was read; bigger jumps, such as a
+// new phase, ease in.
+type ProgressRing struct {
+	shown float32
+	last  time.Time
+}
+
+// Layout draws the ring as large as fits in the constraints. progress
+// was read at the given time and grows by rate every second.
+func (r *ProgressRing) Layout(gtx layout.Context, progress, rate float32, at time.Time, fg, track color.NRGBA, width unit.Dp) layout.Dimensions {
+	target := progress
+	if rate > 0 {
+		// never more than a second ahead, in case an update is late
+		dt := min(max(gtx.Now.Sub(at).Seconds(), 0), 1)
+		target = min(progress+rate*float32(dt), 1)
+		gtx.Execute(op.InvalidateCmd{})
+	}
+	r.step(gtx, target)
+
+	size := min(gtx.Constraints.Max.X, gtx.Constraints.Max.Y)
+	stroke := float32(gtx.Dp(width))
+	center := f32.Pt(float32(size)/2, float32(size)/2)
+	radius := float32(size)/2 - stroke/2
+
+	paint.FillShape(gtx.Ops, track, clip.Stroke{Path: arc(gtx.Ops, center, radius, 1), Width: stroke}.Op())
+	if r.shown > 0 {
+		paint.FillShape(gtx.Ops, fg, clip.Stroke{Path: arc(gtx.Ops, center, radius, r.shown), Width: stroke}.Op())
+	}
+	return layout.Dimensions{Size: image.Pt(size, size)}
+}
+
+func (r *ProgressRing) step(gtx layout.Context, target float32) {
+	now := gtx.Now
+	if r.last.IsZero() {
+		r.shown, r.last = target, now
+		return
+	}
+	dt := float32(now.Sub(r.last).Seconds())
+	r.last = now
+
+	// the timer's own movement is followed exactly
+	diff := target - r.shown
+	if diff > -0.05 && diff < 0.05 {
+		r.shown = target
+		return
+	}
+	// close most of the gap within half a second
+	r.shown += diff * min(dt*6, 1)
+	gtx.Execute(op.InvalidateCmd{})
+}
+
+// arc is fraction of a circle, starting at the top.
+func arc(ops *op.Ops, center f32.Point, radius, fraction float32) clip.PathSpec {
+	var p clip.Path
+	p.Begin(ops)
+	p.MoveTo(f32.Pt(center.X, center.Y-radius))
+	p.ArcTo(center, center, fraction*2*math.Pi)
+	return p.End()
+}
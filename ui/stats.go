@@ -0,0 +1,207 @@
+package ui
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+	"github.com/catalinfl/nuisance/history"
+)
+
+// what the export buttons on the Stats tab write out
+const (
+	ExportToday = iota
+	ExportWeek
+	ExportAll
+)
+
+var ExportScopes = []string{"Today", "This week", "All"}
+
+func StatsContent(gtx layout.Context, th *material.Theme, btns *Buttons, state *AppState) layout.Dimensions {
+	st := state.Stats
+	week := st.Days
+	if len(week) > 7 {
+		week = week[len(week)-7:]
+	}
+
+	return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				label := material.H6(th, "Today")
+				label.TextSize = unit.Sp(14)
+				return label.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				text := fmt.Sprintf("%.0f min focused · %d pomodoros · streak %d days", st.TodayMinutes, st.TodayPomodoros, st.Streak)
+				label := material.Body2(th, text)
+				label.TextSize = unit.Sp(12)
+				return label.Layout(gtx)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				label := material.H6(th, fmt.Sprintf("Last 7 days (%.0f min this week)", st.WeekMinutes))
+				label.TextSize = unit.Sp(14)
+				return label.Layout(gtx)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(4)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return barChart(gtx, th, week)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				var children []layout.FlexChild
+				for _, d := range week {
+					text := d.Day.Format("Mon")[:2]
+					children = append(children, layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						label := material.Caption(th, text)
+						return layout.N.Layout(gtx, label.Layout)
+					}))
+				}
+				return layout.Flex{}.Layout(gtx, children...)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				label := material.H6(th, fmt.Sprintf("Calendar (%d pomodoros total)", st.TotalPomodoros))
+				label.TextSize = unit.Sp(14)
+				return label.Layout(gtx)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(4)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return heatmap(gtx, th, st.Days)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				label := material.H6(th, "Most interrupted hours")
+				label.TextSize = unit.Sp(14)
+				return label.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				text := "No interruptions logged"
+				if top := st.TopInterruptionHours(3); len(top) > 0 {
+					text = ""
+					for i, h := range top {
+						if i > 0 {
+							text += " · "
+						}
+						text += fmt.Sprintf("%02d:00 (%d)", h.Hour, h.Count)
+					}
+				}
+				label := material.Body2(th, text)
+				label.TextSize = unit.Sp(12)
+				return label.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				text := fmt.Sprintf("This week: %d internal, %d external", st.WeekInternal, st.WeekExternal)
+				label := material.Body2(th, text)
+				label.TextSize = unit.Sp(12)
+				return label.Layout(gtx)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						label := material.H6(th, "Export")
+						label.TextSize = unit.Sp(14)
+						return label.Layout(gtx)
+					}),
+					layout.Flexed(1, layout.Spacer{}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						btn := material.Button(th, btns.ExportScope, ExportScopes[state.ExportScope])
+						btn.Inset = layout.UniformInset(unit.Dp(4))
+						btn.TextSize = unit.Sp(11)
+						return btn.Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						btn := material.Button(th, btns.ExportCSV, "CSV")
+						btn.Inset = layout.UniformInset(unit.Dp(4))
+						btn.TextSize = unit.Sp(11)
+						return btn.Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						btn := material.Button(th, btns.ExportJSON, "JSON")
+						btn.Inset = layout.UniformInset(unit.Dp(4))
+						btn.TextSize = unit.Sp(11)
+						return btn.Layout(gtx)
+					}),
+				)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if state.ExportStatus == "" {
+					return layout.Dimensions{}
+				}
+				return material.Caption(th, state.ExportStatus).Layout(gtx)
+			}),
+		)
+	})
+}
+
+func barChart(gtx layout.Context, th *material.Theme, days []history.DayStat) layout.Dimensions {
+	width := gtx.Constraints.Max.X
+	height := gtx.Dp(unit.Dp(80))
+	if len(days) == 0 {
+		return layout.Dimensions{Size: image.Pt(width, height)}
+	}
+
+	peak := 1.0
+	for _, d := range days {
+		if d.Minutes > peak {
+			peak = d.Minutes
+		}
+	}
+
+	slot := width / len(days)
+	gap := gtx.Dp(unit.Dp(6))
+	for i, d := range days {
+		h := int(float64(height) * d.Minutes / peak)
+		if d.Minutes > 0 && h < 2 {
+			h = 2
+		}
+		x := i*slot + gap/2
+		bar := image.Rect(x, height-h, x+slot-gap, height)
+		paint.FillShape(gtx.Ops, th.Palette.ContrastBg, clip.Rect(bar).Op())
+	}
+
+	baseline := image.Rect(0, height-1, width, height)
+	paint.FillShape(gtx.Ops, color.NRGBA{A: 60}, clip.Rect(baseline).Op())
+	return layout.Dimensions{Size: image.Pt(width, height)}
+}
+
+// heatmap draws one column per week and one row per weekday.
+func heatmap(gtx layout.Context, th *material.Theme, days []history.DayStat) layout.Dimensions {
+	cell := gtx.Dp(unit.Dp(14))
+	gap := gtx.Dp(unit.Dp(3))
+
+	peak := 1.0
+	for _, d := range days {
+		if d.Minutes > peak {
+			peak = d.Minutes
+		}
+	}
+
+	weeks := (len(days) + 6) / 7
+	for i, d := range days {
+		col, row := i/7, i%7
+		x := col * (cell + gap)
+		y := row * (cell + gap)
+
+		c := color.NRGBA{R: 220, G: 220, B: 220, A: 255}
+		if d.Minutes > 0 {
+			c = th.Palette.ContrastBg
+			c.A = uint8(70 + 185*d.Minutes/peak)
+		}
+		rect := image.Rect(x, y, x+cell, y+cell)
+		paint.FillShape(gtx.Ops, c, clip.UniformRRect(rect, gtx.Dp(unit.Dp(2))).Op(gtx.Ops))
+	}
+
+	return layout.Dimensions{Size: image.Pt(weeks*(cell+gap), 7*(cell+gap))}
+}
@@ -0,0 +1,141 @@
+package ui
+
+import (
+	"fmt"
+
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type TaskButtons struct {
+	TitleEditor *widget.Editor
+	EstInc      *widget.Clickable
+	EstDec      *widget.Clickable
+	Add         *widget.Clickable
+	Select      []*widget.Clickable
+	Done        []*widget.Clickable
+	Remove      []*widget.Clickable
+}
+
+func NewTaskButtons() *TaskButtons {
+	editor := new(widget.Editor)
+	editor.SingleLine = true
+	editor.Submit = true
+	return &TaskButtons{
+		TitleEditor: editor,
+		EstInc:      new(widget.Clickable),
+		EstDec:      new(widget.Clickable),
+		Add:         new(widget.Clickable),
+	}
+}
+
+// grow makes sure there is a clickable for every task row.
+func (btns *TaskButtons) grow(n int) {
+	for len(btns.Select) < n {
+		btns.Select = append(btns.Select, new(widget.Clickable))
+		btns.Done = append(btns.Done, new(widget.Clickable))
+		btns.Remove = append(btns.Remove, new(widget.Clickable))
+	}
+}
+
+func TasksContent(gtx layout.Context, th *material.Theme, btns *TaskButtons, state *AppState) layout.Dimensions {
+	btns.grow(len(state.Tasks))
+
+	return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				label := material.H6(th, "Tasks")
+				label.TextSize = unit.Sp(14)
+				return label.Layout(gtx)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(4)}.Layout),
+
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						ed := material.Editor(th, btns.TitleEditor, "New task")
+						ed.TextSize = unit.Sp(12)
+						return ed.Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						btn := material.Button(th, btns.EstDec, "-")
+						btn.Inset = layout.UniformInset(unit.Dp(4))
+						btn.TextSize = unit.Sp(12)
+						return btn.Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						label := material.Body1(th, fmt.Sprintf("%d", state.TaskEstimate))
+						label.TextSize = unit.Sp(14)
+						return label.Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						btn := material.Button(th, btns.EstInc, "+")
+						btn.Inset = layout.UniformInset(unit.Dp(4))
+						btn.TextSize = unit.Sp(12)
+						return btn.Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						btn := material.Button(th, btns.Add, "+ Add")
+						btn.Inset = layout.UniformInset(unit.Dp(4))
+						btn.TextSize = unit.Sp(11)
+						return btn.Layout(gtx)
+					}),
+				)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(2)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				label := material.Caption(th, "Tap a task to work on it next. Estimates are in pomodoros.")
+				return label.Layout(gtx)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(6)}.Layout),
+
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					taskList(th, btns, state)...,
+				)
+			}),
+		)
+	})
+}
+
+func taskList(th *material.Theme, btns *TaskButtons, state *AppState) []layout.FlexChild {
+	var children []layout.FlexChild
+	for i, task := range state.Tasks {
+		idx := i
+		name := fmt.Sprintf("%s (%d/%d)", task.Title, task.Actual, task.Estimate)
+		if task.Done {
+			name = "✓ " + name
+		}
+		doneText := "Done"
+		if task.Done {
+			doneText = "Undo"
+		}
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					return settingsButton(gtx, th, btns.Select[idx], name, state.SelectedTask == idx)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					btn := material.Button(th, btns.Done[idx], doneText)
+					btn.Inset = layout.UniformInset(unit.Dp(4))
+					btn.TextSize = unit.Sp(11)
+					return btn.Layout(gtx)
+				}),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					btn := material.Button(th, btns.Remove[idx], "x")
+					btn.Inset = layout.UniformInset(unit.Dp(4))
+					btn.TextSize = unit.Sp(11)
+					return btn.Layout(gtx)
+				}),
+			)
+		}))
+	}
+	return children
+}
@@ -6,8 +6,10 @@ import (
 	"image/color"
 	_ "image/jpeg"
 	_ "image/png"
+	"math"
 	"os"
 	"path/filepath"
+	"time"
 
 	"gioui.org/f32"
 	"gioui.org/layout"
@@ -17,6 +19,11 @@ import (
 	"gioui.org/unit"
 	"gioui.org/widget"
 	"gioui.org/widget/material"
+	"github.com/catalinfl/nuisance/history"
+	"github.com/catalinfl/nuisance/pomodoro"
+	"github.com/catalinfl/nuisance/sound"
+	"github.com/catalinfl/nuisance/speech"
+	"github.com/catalinfl/nuisance/tasks"
 )
 
 type AppState struct {
@@ -28,18 +35,61 @@ type AppState struct {
 	PomodoroMode    string
 	WorkMinutes     int
 	BreakMinutes    int
+	Flowtime        bool
+	FlowRatio       int
+	FlowActive      bool
 	CustomWebsites  []string
 	WebsiteInput    string
 	BackgroundImage *image.Image
+	Stats           history.Stats
+	ExportScope     int
+	ExportStatus    string
+	Tasks           []tasks.Task
+	SelectedTask    int
+	TaskEstimate    int
+	CurrentTask     string
+	Working         bool
+	InternalCount   int
+	ExternalCount   int
+	ResumePrompt    string
+	SoundEnabled    map[sound.Event]bool
+	Ambient         sound.Noise
+	Tick            bool
+	AlarmSilence    int
+	SoundPack       string
+	Announce        map[speech.Event]bool
+	WarnMinutes     map[int]bool
+	PulseUntil      time.Time
+	Progress        float32 // of the current phase, 0 to 1
+	ProgressRate    float32 // per second while the timer runs
+	ProgressAt      time.Time
+	TimerMode       pomodoro.Mode
+	MiniOpen        bool
 }
 
+// WarnPresets are the remaining-time warnings offered in Settings.
+var WarnPresets = []int{1, 2, 5, 10}
+
 type Buttons struct {
 	Tab1      *widget.Clickable
 	Tab2      *widget.Clickable
+	Tab3      *widget.Clickable
+	Tab4      *widget.Clickable
 	Toggle    *widget.Clickable
+	Mini      *widget.Clickable
 	PomoPlay  *widget.Clickable
 	PomoPause *widget.Clickable
 	PomoReset *widget.Clickable
+	Internal  *widget.Clickable
+	External  *widget.Clickable
+	Note      *widget.Editor
+	ResumeYes *widget.Clickable
+	ResumeNo  *widget.Clickable
+	Ring      *ProgressRing
+
+	ExportScope *widget.Clickable
+	ExportCSV   *widget.Clickable
+	ExportJSON  *widget.Clickable
 }
 
 type SettingsButtons struct {
@@ -54,18 +104,47 @@ type SettingsButtons struct {
 	WorkDec        *widget.Clickable
 	BreakInc       *widget.Clickable
 	BreakDec       *widget.Clickable
+	FlowToggle     *widget.Clickable
+	RatioInc       *widget.Clickable
+	RatioDec       *widget.Clickable
 	AddWebsite     *widget.Clickable
 	WebsiteEditor  *widget.Editor
+	Volume         *widget.Float
+	SoundToggle    map[sound.Event]*widget.Clickable
+	SoundFile      map[sound.Event]*widget.Editor
+	AmbientCycle   *widget.Clickable
+	TickToggle     *widget.Clickable
+	SilenceInc     *widget.Clickable
+	SilenceDec     *widget.Clickable
+	PackCycle      *widget.Clickable
+	AnnounceToggle map[speech.Event]*widget.Clickable
+	WarnToggle     map[int]*widget.Clickable
+	List           *widget.List
 }
 
 func NewButtons() *Buttons {
+	note := new(widget.Editor)
+	note.SingleLine = true
 	return &Buttons{
 		Tab1:      new(widget.Clickable),
 		Tab2:      new(widget.Clickable),
+		Tab3:      new(widget.Clickable),
+		Tab4:      new(widget.Clickable),
 		Toggle:    new(widget.Clickable),
+		Mini:      new(widget.Clickable),
 		PomoPlay:  new(widget.Clickable),
 		PomoPause: new(widget.Clickable),
 		PomoReset: new(widget.Clickable),
+		Internal:  new(widget.Clickable),
+		External:  new(widget.Clickable),
+		Note:      note,
+		ResumeYes: new(widget.Clickable),
+		ResumeNo:  new(widget.Clickable),
+		Ring:      new(ProgressRing),
+
+		ExportScope: new(widget.Clickable),
+		ExportCSV:   new(widget.Clickable),
+		ExportJSON:  new(widget.Clickable),
 	}
 }
 
@@ -73,6 +152,20 @@ func NewSettingsButtons() *SettingsButtons {
 	editor := new(widget.Editor)
 	editor.SingleLine = true
 	editor.Submit = true
+	toggles := make(map[sound.Event]*widget.Clickable)
+	files := make(map[sound.Event]*widget.Editor)
+	for _, e := range sound.Events {
+		toggles[e] = new(widget.Clickable)
+		files[e] = &widget.Editor{SingleLine: true, Submit: true}
+	}
+	announce := make(map[speech.Event]*widget.Clickable)
+	for _, e := range speech.Events {
+		announce[e] = new(widget.Clickable)
+	}
+	warn := make(map[int]*widget.Clickable)
+	for _, m := range WarnPresets {
+		warn[m] = new(widget.Clickable)
+	}
 	return &SettingsButtons{
 		BlockFacebook:  new(widget.Clickable),
 		BlockYouTube:   new(widget.Clickable),
@@ -85,8 +178,22 @@ func NewSettingsButtons() *SettingsButtons {
 		WorkDec:        new(widget.Clickable),
 		BreakInc:       new(widget.Clickable),
 		BreakDec:       new(widget.Clickable),
+		FlowToggle:     new(widget.Clickable),
+		RatioInc:       new(widget.Clickable),
+		RatioDec:       new(widget.Clickable),
 		AddWebsite:     new(widget.Clickable),
 		WebsiteEditor:  editor,
+		Volume:         new(widget.Float),
+		SoundToggle:    toggles,
+		SoundFile:      files,
+		AmbientCycle:   new(widget.Clickable),
+		TickToggle:     new(widget.Clickable),
+		SilenceInc:     new(widget.Clickable),
+		SilenceDec:     new(widget.Clickable),
+		PackCycle:      new(widget.Clickable),
+		AnnounceToggle: announce,
+		WarnToggle:     warn,
+		List:           &widget.List{List: layout.List{Axis: layout.Vertical}},
 	}
 }
 
@@ -99,6 +206,12 @@ func TabBar(gtx layout.Context, th *material.Theme, btns *Buttons, currentTab in
 		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
 			return CustomTabButton(gtx, th, btns.Tab2, "Settings", currentTab == 1)
 		}),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return CustomTabButton(gtx, th, btns.Tab3, "Stats", currentTab == 2)
+		}),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return CustomTabButton(gtx, th, btns.Tab4, "Tasks", currentTab == 3)
+		}),
 	)
 }
 
@@ -130,10 +243,16 @@ func CustomTabButton(gtx layout.Context, th *material.Theme, btn *widget.Clickab
 	})
 }
 
-func TabContent(gtx layout.Context, th *material.Theme, btns *Buttons, state *AppState, currentTab int) layout.Dimensions {
+func TabContent(gtx layout.Context, th *material.Theme, btns *Buttons, taskBtns *TaskButtons, state *AppState, currentTab int) layout.Dimensions {
 	if currentTab == 0 {
 		return PomodoroContent(gtx, th, btns, state)
 	}
+	if currentTab == 2 {
+		return StatsContent(gtx, th, btns, state)
+	}
+	if currentTab == 3 {
+		return TasksContent(gtx, th, taskBtns, state)
+	}
 	return layout.Dimensions{}
 }
 
@@ -171,19 +290,36 @@ func PomodoroContent(gtx layout.Context, th *material.Theme, btns *Buttons, stat
 			return layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 				return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx,
 					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-						label := material.H1(th, state.PomodoroTime)
-						return label.Layout(gtx)
+						if state.ResumePrompt == "" {
+							return layout.Dimensions{}
+						}
+						return resumeBar(gtx, th, btns, state.ResumePrompt)
+					}),
+					// the ring gets whatever height the controls leave
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						return timerRing(gtx, th, btns, state)
 					}),
 					layout.Rigid(layout.Spacer{Height: unit.Dp(4)}.Layout),
 					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 						label := material.Body2(th, state.PomodoroMode)
 						return label.Layout(gtx)
 					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						if state.CurrentTask == "" {
+							return layout.Dimensions{}
+						}
+						label := material.Caption(th, state.CurrentTask)
+						return label.Layout(gtx)
+					}),
 					layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
 					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 						return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
 							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-								btn := material.Button(th, btns.PomoPlay, "Start")
+								text := "Start"
+								if state.FlowActive {
+									text = "Break"
+								}
+								btn := material.Button(th, btns.PomoPlay, text)
 								btn.Inset = layout.UniformInset(unit.Dp(6))
 								btn.TextSize = unit.Sp(12)
 								return btn.Layout(gtx)
@@ -204,13 +340,122 @@ func PomodoroContent(gtx layout.Context, th *material.Theme, btns *Buttons, stat
 							}),
 						)
 					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						if !state.Working {
+							return layout.Dimensions{}
+						}
+						return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+							return interruptionBar(gtx, th, btns, state)
+						})
+					}),
 				)
 			})
 		}),
 	)
 }
 
+// timerRing is the clock inside the progress ring, up to 184dp across
+// and smaller when the window has less room.
+func timerRing(gtx layout.Context, th *material.Theme, btns *Buttons, state *AppState) layout.Dimensions {
+	full := gtx.Dp(unit.Dp(184))
+	size := min(gtx.Constraints.Max.X, gtx.Constraints.Max.Y, full)
+	if size <= 0 {
+		return layout.Dimensions{}
+	}
+	gtx.Constraints = layout.Exact(image.Pt(size, size))
+	return layout.Stack{Alignment: layout.Center}.Layout(gtx,
+		layout.Expanded(func(gtx layout.Context) layout.Dimensions {
+			track := color.NRGBA{A: 40}
+			return btns.Ring.Layout(gtx, state.Progress, state.ProgressRate, state.ProgressAt, ModeColor(state.TimerMode), track, unit.Dp(8))
+		}),
+		layout.Stacked(func(gtx layout.Context) layout.Dimensions {
+			label := material.H3(th, state.PomodoroTime)
+			label.TextSize = label.TextSize * unit.Sp(size) / unit.Sp(full)
+			if now := gtx.Now; now.Before(state.PulseUntil) {
+				label.Color = pulseColor(th, state.PulseUntil.Sub(now))
+				gtx.Execute(op.InvalidateCmd{})
+			}
+			return label.Layout(gtx)
+		}),
+	)
+}
+
+// pulseColor fades the timer between the text and accent colors twice a
+// second while a warning is fresh.
+func pulseColor(th *material.Theme, left time.Duration) color.NRGBA {
+	t := float32((1 - math.Cos(2*math.Pi*2*left.Seconds())) / 2)
+	mix := func(a, b uint8) uint8 { return uint8(float32(a) + (float32(b)-float32(a))*t) }
+	fg, accent := th.Palette.Fg, th.Palette.ContrastBg
+	return color.NRGBA{R: mix(fg.R, accent.R), G: mix(fg.G, accent.G), B: mix(fg.B, accent.B), A: 255}
+}
+
+func resumeBar(gtx layout.Context, th *material.Theme, btns *Buttons, prompt string) layout.Dimensions {
+	return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Body2(th, prompt)
+			label.TextSize = unit.Sp(12)
+			return label.Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Width: unit.Dp(6)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			btn := material.Button(th, btns.ResumeYes, "Resume")
+			btn.Inset = layout.UniformInset(unit.Dp(4))
+			btn.TextSize = unit.Sp(11)
+			return btn.Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			btn := material.Button(th, btns.ResumeNo, "Discard")
+			btn.Inset = layout.UniformInset(unit.Dp(4))
+			btn.TextSize = unit.Sp(11)
+			return btn.Layout(gtx)
+		}),
+	)
+}
+
+func interruptionBar(gtx layout.Context, th *material.Theme, btns *Buttons, state *AppState) layout.Dimensions {
+	return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					gtx.Constraints.Min.X = gtx.Dp(unit.Dp(110))
+					gtx.Constraints.Max.X = gtx.Constraints.Min.X
+					ed := material.Editor(th, btns.Note, "note (optional)")
+					ed.TextSize = unit.Sp(11)
+					return ed.Layout(gtx)
+				}),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(6)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					btn := material.Button(th, btns.Internal, "Internal")
+					btn.Inset = layout.UniformInset(unit.Dp(4))
+					btn.TextSize = unit.Sp(11)
+					return btn.Layout(gtx)
+				}),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					btn := material.Button(th, btns.External, "External")
+					btn.Inset = layout.UniformInset(unit.Dp(4))
+					btn.TextSize = unit.Sp(11)
+					return btn.Layout(gtx)
+				}),
+			)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			text := fmt.Sprintf("Interrupted: %d internal, %d external (Ctrl+I / Ctrl+E)", state.InternalCount, state.ExternalCount)
+			label := material.Caption(th, text)
+			return label.Layout(gtx)
+		}),
+	)
+}
+
 func SettingsContent(gtx layout.Context, th *material.Theme, mainBtns *Buttons, btns *SettingsButtons, state *AppState) layout.Dimensions {
+	// one tall item, scrolled as a whole
+	return material.List(th, btns.List).Layout(gtx, 1, func(gtx layout.Context, _ int) layout.Dimensions {
+		return settingsItems(gtx, th, mainBtns, btns, state)
+	})
+}
+
+func settingsItems(gtx layout.Context, th *material.Theme, mainBtns *Buttons, btns *SettingsButtons, state *AppState) layout.Dimensions {
 	return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
@@ -282,6 +527,53 @@ func SettingsContent(gtx layout.Context, th *material.Theme, mainBtns *Buttons,
 					}),
 				)
 			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(4)}.Layout),
+
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return settingsButton(gtx, th, btns.FlowToggle, "Flowtime (count up)", state.Flowtime)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(4)}.Layout),
+
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Flexed(0.5, func(gtx layout.Context) layout.Dimensions {
+						label := material.Body2(th, "Flow break ratio:")
+						label.TextSize = unit.Sp(12)
+						return label.Layout(gtx)
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						btn := material.Button(th, btns.RatioDec, "-")
+						btn.Inset = layout.UniformInset(unit.Dp(4))
+						btn.TextSize = unit.Sp(12)
+						return btn.Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return layout.Inset{Top: unit.Dp(4), Bottom: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+							label := material.Body1(th, fmt.Sprintf("1:%d", state.FlowRatio))
+							label.TextSize = unit.Sp(14)
+							return label.Layout(gtx)
+						})
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						btn := material.Button(th, btns.RatioInc, "+")
+						btn.Inset = layout.UniformInset(unit.Dp(4))
+						btn.TextSize = unit.Sp(12)
+						return btn.Layout(gtx)
+					}),
+				)
+			}),
+
+			layout.Rigid(layout.Spacer{Height: unit.Dp(4)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return warnRow(gtx, th, btns, state)
+			}),
+
+			layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return soundSettings(gtx, th, btns, state)
+			}),
 
 			layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
@@ -370,12 +662,168 @@ func SettingsContent(gtx layout.Context, th *material.Theme, mainBtns *Buttons,
 			}),
 			layout.Rigid(layout.Spacer{Height: unit.Dp(4)}.Layout),
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-				return ToggleButton(gtx, th, mainBtns.Toggle, state.AlwaysOnTop)
+				return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return ToggleButton(gtx, th, mainBtns.Toggle, state.AlwaysOnTop)
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return MiniButton(gtx, th, mainBtns.Mini, state.MiniOpen)
+					}),
+				)
 			}),
 		)
 	})
 }
 
+func warnRow(gtx layout.Context, th *material.Theme, btns *SettingsButtons, state *AppState) layout.Dimensions {
+	children := []layout.FlexChild{
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			label := material.Body2(th, "Warn (min left):")
+			label.TextSize = unit.Sp(12)
+			return label.Layout(gtx)
+		}),
+	}
+	for _, m := range WarnPresets {
+		children = append(children,
+			layout.Rigid(layout.Spacer{Width: unit.Dp(2)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return CustomTabButton(gtx, th, btns.WarnToggle[m], fmt.Sprint(m), state.WarnMinutes[m])
+			}),
+		)
+	}
+	return layout.Flex{Alignment: layout.Middle}.Layout(gtx, children...)
+}
+
+func soundSettings(gtx layout.Context, th *material.Theme, btns *SettingsButtons, state *AppState) layout.Dimensions {
+	children := []layout.FlexChild{
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.H6(th, "Sounds")
+			label.TextSize = unit.Sp(14)
+			return label.Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(4)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					label := material.Body2(th, "Volume:")
+					label.TextSize = unit.Sp(12)
+					return label.Layout(gtx)
+				}),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+				layout.Flexed(1, material.Slider(th, btns.Volume).Layout),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					label := material.Body1(th, fmt.Sprintf("%d%%", int(btns.Volume.Value*100+0.5)))
+					label.TextSize = unit.Sp(12)
+					return label.Layout(gtx)
+				}),
+			)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(4)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+				layout.Flexed(0.5, func(gtx layout.Context) layout.Dimensions {
+					label := material.Body2(th, "Sound pack:")
+					label.TextSize = unit.Sp(12)
+					return label.Layout(gtx)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					btn := material.Button(th, btns.PackCycle, state.SoundPack)
+					btn.Inset = layout.UniformInset(unit.Dp(4))
+					btn.TextSize = unit.Sp(12)
+					return btn.Layout(gtx)
+				}),
+			)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(2)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+				layout.Flexed(0.5, func(gtx layout.Context) layout.Dimensions {
+					label := material.Body2(th, "While working:")
+					label.TextSize = unit.Sp(12)
+					return label.Layout(gtx)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					btn := material.Button(th, btns.AmbientCycle, state.Ambient.Label())
+					btn.Inset = layout.UniformInset(unit.Dp(4))
+					btn.TextSize = unit.Sp(12)
+					return btn.Layout(gtx)
+				}),
+			)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(2)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return settingsButton(gtx, th, btns.TickToggle, "Clock tick", state.Tick)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(4)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			silence := "never"
+			if state.AlarmSilence > 0 {
+				silence = fmt.Sprintf("%d min", state.AlarmSilence)
+			}
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+				layout.Flexed(0.5, func(gtx layout.Context) layout.Dimensions {
+					label := material.Body2(th, "Silence alarm after:")
+					label.TextSize = unit.Sp(12)
+					return label.Layout(gtx)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					btn := material.Button(th, btns.SilenceDec, "-")
+					btn.Inset = layout.UniformInset(unit.Dp(4))
+					btn.TextSize = unit.Sp(12)
+					return btn.Layout(gtx)
+				}),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return layout.Inset{Top: unit.Dp(4), Bottom: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						label := material.Body1(th, silence)
+						label.TextSize = unit.Sp(14)
+						return label.Layout(gtx)
+					})
+				}),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					btn := material.Button(th, btns.SilenceInc, "+")
+					btn.Inset = layout.UniformInset(unit.Dp(4))
+					btn.TextSize = unit.Sp(12)
+					return btn.Layout(gtx)
+				}),
+			)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(4)}.Layout),
+	}
+	for _, e := range sound.Events {
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+				layout.Flexed(0.5, func(gtx layout.Context) layout.Dimensions {
+					return settingsButton(gtx, th, btns.SoundToggle[e], e.Label(), state.SoundEnabled[e])
+				}),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+				layout.Flexed(0.5, func(gtx layout.Context) layout.Dimensions {
+					ed := material.Editor(th, btns.SoundFile[e], "default (Enter to save)")
+					ed.TextSize = unit.Sp(11)
+					return ed.Layout(gtx)
+				}),
+			)
+		}))
+	}
+	children = append(children,
+		layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.H6(th, "Spoken announcements")
+			label.TextSize = unit.Sp(14)
+			return label.Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(4)}.Layout),
+	)
+	for _, e := range speech.Events {
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return settingsButton(gtx, th, btns.AnnounceToggle[e], e.Label(), state.Announce[e])
+		}))
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+}
+
 func customWebsiteList(th *material.Theme, state *AppState) []layout.FlexChild {
 	var children []layout.FlexChild
 	for i, site := range state.CustomWebsites {
@@ -464,7 +912,7 @@ func ToggleButton(gtx layout.Context, th *material.Theme, btn *widget.Clickable,
 	})
 }
 
-func Layout(gtx layout.Context, th *material.Theme, btns *Buttons, settingsBtns *SettingsButtons, state *AppState) layout.Dimensions {
+func Layout(gtx layout.Context, th *material.Theme, btns *Buttons, settingsBtns *SettingsButtons, taskBtns *TaskButtons, state *AppState) layout.Dimensions {
 	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 			return TabBar(gtx, th, btns, state.CurrentTab)
@@ -474,7 +922,7 @@ func Layout(gtx layout.Context, th *material.Theme, btns *Buttons, settingsBtns
 			if state.CurrentTab == 1 {
 				return SettingsContent(gtx, th, btns, settingsBtns, state)
 			}
-			return TabContent(gtx, th, btns, state, state.CurrentTab)
+			return TabContent(gtx, th, btns, taskBtns, state, state.CurrentTab)
 		}),
 	)
 }
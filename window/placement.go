@@ -0,0 +1,33 @@
+package window
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// Placement is a window position remembered between runs.
+type Placement struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// LoadPlacement reads a placement saved by SavePlacement; ok is false if
+// there is none.
+func LoadPlacement(path string) (p Placement, ok bool) {
+	input, err := os.ReadFile(path)
+	if err != nil {
+		return p, false
+	}
+	if err := json.Unmarshal(input, &p); err != nil {
+		return p, false
+	}
+	return p, true
+}
+
+func SavePlacement(path string, p Placement) error {
+	output, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, output, 0644)
+}
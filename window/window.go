@@ -1,59 +1,32 @@
 package window
 
-import (
-	"os"
-	"runtime"
-	"syscall"
-	"unsafe"
-)
+// WindowController is implemented per platform: user32 on Windows, X11
+// (EWMH) on Linux and a no-op elsewhere. Window handles are opaque
+// uintptrs: an HWND on Windows, an X11 window id on Linux.
+type WindowController interface {
+	FindWindowByTitle(title string) uintptr
+	SetAlwaysOnTop(hwnd uintptr, enable bool) error
+	Focus(hwnd uintptr) error
+	// SetVisible hides the window, e.g. while the tray icon stands in for
+	// it, or shows it again.
+	SetVisible(hwnd uintptr, visible bool) error
+	// Position and Move use screen coordinates of the window's top-left
+	// corner.
+	Position(hwnd uintptr) (x, y int, err error)
+	Move(hwnd uintptr, x, y int) error
+}
 
 type WindowHandler struct{}
 
-var (
-	user32           = syscall.NewLazyDLL("user32.dll")
-	procSetWindowPos = user32.NewProc("SetWindowPos")
-	procIsWindow     = user32.NewProc("IsWindow")
-	procFindWindowW  = user32.NewProc("FindWindowW")
-)
-
-const (
-	HWND_TOPMOST   = ^uintptr(0)
-	HWND_NOTOPMOST = ^uintptr(1)
-	SWP_NOMOVE     = 0x0002
-	SWP_NOSIZE     = 0x0001
-	SWP_SHOWWINDOW = 0x0040
-)
-
-func (h *WindowHandler) FindWindowByTitle(title string) uintptr {
-	name, _ := syscall.UTF16PtrFromString(title)
-	hwnd, _, _ := procFindWindowW.Call(0, uintptr(unsafe.Pointer(name)))
-	return hwnd
-}
-
-func (h *WindowHandler) SetAlwaysOnTop(hwnd uintptr, enable bool) error {
-	if hwnd == 0 {
-		return nil
-	}
-	runtime.LockOSThread()
-	defer runtime.UnlockOSThread()
+var _ WindowController = (*WindowHandler)(nil)
 
-	is, _, _ := procIsWindow.Call(hwnd)
-	if is == 0 {
-		return nil
-	}
+// NoopController is a WindowController that does nothing, for tests and
+// platforms without window management.
+type NoopController struct{}
 
-	var pos uintptr
-	if enable {
-		pos = HWND_TOPMOST
-	} else {
-		pos = HWND_NOTOPMOST
-	}
-	r1, _, err := procSetWindowPos.Call(hwnd, pos, 0, 0, 0, 0, SWP_NOMOVE|SWP_NOSIZE|SWP_SHOWWINDOW)
-	if r1 == 0 {
-		if err != syscall.Errno(0) {
-			return err
-		}
-		return os.ErrInvalid
-	}
-	return nil
-}
+func (NoopController) FindWindowByTitle(title string) uintptr         { return 0 }
+func (NoopController) SetAlwaysOnTop(hwnd uintptr, enable bool) error { return nil }
+func (NoopController) Focus(hwnd uintptr) error                       { return nil }
+func (NoopController) SetVisible(hwnd uintptr, visible bool) error    { return nil }
+func (NoopController) Position(hwnd uintptr) (int, int, error)        { return 0, 0, nil }
+func (NoopController) Move(hwnd uintptr, x, y int) error              { return nil }
@@ -0,0 +1,178 @@
+package window
+
+import (
+	"os"
+	"sync"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+)
+
+// The X11 side talks EWMH to the window manager. Under a pure Wayland
+// session there is no X server to reach, so lookups return 0 and the
+// setters do nothing.
+
+var (
+	x11Once sync.Once
+	x11Conn *xgb.Conn
+	x11Root xproto.Window
+	x11Err  error
+)
+
+func connectX11() (*xgb.Conn, xproto.Window, error) {
+	x11Once.Do(func() {
+		x11Conn, x11Err = xgb.NewConn()
+		if x11Err != nil {
+			return
+		}
+		x11Root = xproto.Setup(x11Conn).DefaultScreen(x11Conn).Root
+	})
+	return x11Conn, x11Root, x11Err
+}
+
+func atom(conn *xgb.Conn, name string) (xproto.Atom, error) {
+	reply, err := xproto.InternAtom(conn, false, uint16(len(name)), name).Reply()
+	if err != nil {
+		return 0, err
+	}
+	return reply.Atom, nil
+}
+
+func windowTitle(conn *xgb.Conn, win xproto.Window) string {
+	for _, name := range []string{"_NET_WM_NAME", "WM_NAME"} {
+		prop, err := atom(conn, name)
+		if err != nil {
+			continue
+		}
+		reply, err := xproto.GetProperty(conn, false, win, prop, xproto.GetPropertyTypeAny, 0, 256).Reply()
+		if err == nil && reply.ValueLen > 0 {
+			return string(reply.Value)
+		}
+	}
+	return ""
+}
+
+func (h *WindowHandler) FindWindowByTitle(title string) uintptr {
+	conn, root, err := connectX11()
+	if err != nil {
+		return 0
+	}
+
+	clientList, err := atom(conn, "_NET_CLIENT_LIST")
+	if err != nil {
+		return 0
+	}
+	reply, err := xproto.GetProperty(conn, false, root, clientList, xproto.AtomWindow, 0, 1024).Reply()
+	if err != nil {
+		return 0
+	}
+
+	for i := 0; i+4 <= len(reply.Value); i += 4 {
+		win := xproto.Window(xgb.Get32(reply.Value[i:]))
+		if windowTitle(conn, win) == title {
+			return uintptr(win)
+		}
+	}
+	return 0
+}
+
+// sendRootMessage sends an EWMH client message about win to the window
+// manager.
+func sendRootMessage(win xproto.Window, msgType string, data []uint32) error {
+	conn, root, err := connectX11()
+	if err != nil {
+		return err
+	}
+	typ, err := atom(conn, msgType)
+	if err != nil {
+		return err
+	}
+
+	for len(data) < 5 {
+		data = append(data, 0)
+	}
+	ev := xproto.ClientMessageEvent{
+		Format: 32,
+		Window: win,
+		Type:   typ,
+		Data:   xproto.ClientMessageDataUnionData32New(data),
+	}
+	mask := uint32(xproto.EventMaskSubstructureRedirect | xproto.EventMaskSubstructureNotify)
+	return xproto.SendEventChecked(conn, false, root, mask, string(ev.Bytes())).Check()
+}
+
+func (h *WindowHandler) SetAlwaysOnTop(hwnd uintptr, enable bool) error {
+	if hwnd == 0 {
+		return nil
+	}
+	conn, _, err := connectX11()
+	if err != nil {
+		return err
+	}
+	above, err := atom(conn, "_NET_WM_STATE_ABOVE")
+	if err != nil {
+		return err
+	}
+
+	const (
+		stateRemove = 0
+		stateAdd    = 1
+		sourceApp   = 1
+	)
+	action := uint32(stateRemove)
+	if enable {
+		action = stateAdd
+	}
+	return sendRootMessage(xproto.Window(hwnd), "_NET_WM_STATE", []uint32{action, uint32(above), 0, sourceApp})
+}
+
+func (h *WindowHandler) Focus(hwnd uintptr) error {
+	if hwnd == 0 {
+		return nil
+	}
+	const sourceApp = 1
+	return sendRootMessage(xproto.Window(hwnd), "_NET_ACTIVE_WINDOW", []uint32{sourceApp, xproto.TimeCurrentTime})
+}
+
+// SetVisible unmaps the window, which also drops it from the taskbar, or
+// maps it again.
+func (h *WindowHandler) SetVisible(hwnd uintptr, visible bool) error {
+	if hwnd == 0 {
+		return nil
+	}
+	conn, _, err := connectX11()
+	if err != nil {
+		return err
+	}
+	if visible {
+		return xproto.MapWindowChecked(conn, xproto.Window(hwnd)).Check()
+	}
+	return xproto.UnmapWindowChecked(conn, xproto.Window(hwnd)).Check()
+}
+
+func (h *WindowHandler) Position(hwnd uintptr) (int, int, error) {
+	if hwnd == 0 {
+		return 0, 0, os.ErrInvalid
+	}
+	conn, root, err := connectX11()
+	if err != nil {
+		return 0, 0, err
+	}
+	reply, err := xproto.TranslateCoordinates(conn, xproto.Window(hwnd), root, 0, 0).Reply()
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(reply.DstX), int(reply.DstY), nil
+}
+
+func (h *WindowHandler) Move(hwnd uintptr, x, y int) error {
+	if hwnd == 0 {
+		return nil
+	}
+	conn, _, err := connectX11()
+	if err != nil {
+		return err
+	}
+	mask := uint16(xproto.ConfigWindowX | xproto.ConfigWindowY)
+	return xproto.ConfigureWindowChecked(conn, xproto.Window(hwnd), mask, []uint32{uint32(int32(x)), uint32(int32(y))}).Check()
+}
@@ -0,0 +1,29 @@
+//go:build !windows && !linux
+
+package window
+
+import "os"
+
+func (h *WindowHandler) FindWindowByTitle(title string) uintptr {
+	return 0
+}
+
+func (h *WindowHandler) SetAlwaysOnTop(hwnd uintptr, enable bool) error {
+	return nil
+}
+
+func (h *WindowHandler) Focus(hwnd uintptr) error {
+	return nil
+}
+
+func (h *WindowHandler) SetVisible(hwnd uintptr, visible bool) error {
+	return nil
+}
+
+func (h *WindowHandler) Position(hwnd uintptr) (int, int, error) {
+	return 0, 0, os.ErrInvalid
+}
+
+func (h *WindowHandler) Move(hwnd uintptr, x, y int) error {
+	return nil
+}
@@ -0,0 +1,106 @@
+package window
+
+import (
+	"os"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	user32                  = syscall.NewLazyDLL("user32.dll")
+	procSetWindowPos        = user32.NewProc("SetWindowPos")
+	procIsWindow            = user32.NewProc("IsWindow")
+	procFindWindowW         = user32.NewProc("FindWindowW")
+	procSetForegroundWindow = user32.NewProc("SetForegroundWindow")
+	procShowWindow          = user32.NewProc("ShowWindow")
+	procGetWindowRect       = user32.NewProc("GetWindowRect")
+)
+
+const (
+	HWND_TOPMOST   = ^uintptr(0)
+	HWND_NOTOPMOST = ^uintptr(1)
+	SWP_NOMOVE     = 0x0002
+	SWP_NOSIZE     = 0x0001
+	SWP_SHOWWINDOW = 0x0040
+	SWP_NOZORDER   = 0x0004
+	SW_HIDE        = 0
+	SW_SHOW        = 5
+)
+
+func (h *WindowHandler) FindWindowByTitle(title string) uintptr {
+	name, _ := syscall.UTF16PtrFromString(title)
+	hwnd, _, _ := procFindWindowW.Call(0, uintptr(unsafe.Pointer(name)))
+	return hwnd
+}
+
+func (h *WindowHandler) SetAlwaysOnTop(hwnd uintptr, enable bool) error {
+	if hwnd == 0 {
+		return nil
+	}
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	is, _, _ := procIsWindow.Call(hwnd)
+	if is == 0 {
+		return nil
+	}
+
+	var pos uintptr
+	if enable {
+		pos = HWND_TOPMOST
+	} else {
+		pos = HWND_NOTOPMOST
+	}
+	r1, _, err := procSetWindowPos.Call(hwnd, pos, 0, 0, 0, 0, SWP_NOMOVE|SWP_NOSIZE|SWP_SHOWWINDOW)
+	if r1 == 0 {
+		if err != syscall.Errno(0) {
+			return err
+		}
+		return os.ErrInvalid
+	}
+	return nil
+}
+
+func (h *WindowHandler) Focus(hwnd uintptr) error {
+	if hwnd == 0 {
+		return nil
+	}
+	procSetForegroundWindow.Call(hwnd)
+	return nil
+}
+
+func (h *WindowHandler) SetVisible(hwnd uintptr, visible bool) error {
+	if hwnd == 0 {
+		return nil
+	}
+	cmd := uintptr(SW_HIDE)
+	if visible {
+		cmd = SW_SHOW
+	}
+	procShowWindow.Call(hwnd, cmd)
+	return nil
+}
+
+func (h *WindowHandler) Position(hwnd uintptr) (int, int, error) {
+	var rect struct{ Left, Top, Right, Bottom int32 }
+	if hwnd == 0 {
+		return 0, 0, os.ErrInvalid
+	}
+	r1, _, err := procGetWindowRect.Call(hwnd, uintptr(unsafe.Pointer(&rect)))
+	if r1 == 0 {
+		return 0, 0, err
+	}
+	return int(rect.Left), int(rect.Top), nil
+}
+
+func (h *WindowHandler) Move(hwnd uintptr, x, y int) error {
+	if hwnd == 0 {
+		return nil
+	}
+	r1, _, err := procSetWindowPos.Call(hwnd, 0, uintptr(x), uintptr(y), 0, 0, SWP_NOSIZE|SWP_NOZORDER)
+	if r1 == 0 {
+		return err
+	}
+	return nil
+}